@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// agentFile is the persisted form of an auto player.
+type agentFile struct {
+	Side        string          `json:"side"`
+	Rows        int             `json:"rows"`
+	Columns     int             `json:"columns"`
+	Seed        int64           `json:"seed"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	Sessions    []sessionFile   `json:"sessions,omitempty"`
+	Checkpoint  *checkpointFile `json:"checkpoint,omitempty"`
+	Positions   []positionFile  `json:"positions"`
+}
+
+// checkpointFile is the persisted form of the progress of an auto player being
+// trained in epochs and the settings it is trained with.
+type checkpointFile struct {
+	Session      sessionFile `json:"session"`
+	Opponent     string      `json:"opponent"`
+	EvalOpponent string      `json:"evalOpponent"`
+	EpochGames   int         `json:"epochGames"`
+	EvalGames    int         `json:"evalGames"`
+	Target       float64     `json:"target,omitempty"`
+	Patience     int         `json:"patience,omitempty"`
+	MinDelta     float64     `json:"minDelta,omitempty"`
+	GamesPlayed  int         `json:"gamesPlayed"`
+	Epoch        int         `json:"epoch"`
+	BestScore    float64     `json:"bestScore"`
+	Stale        int         `json:"stale"`
+	Stopped      bool        `json:"stopped,omitempty"`
+}
+
+// sessionFile is the persisted form of a train config.
+type sessionFile struct {
+	Games        int          `json:"games"`
+	Seed         int64        `json:"seed"`
+	LearningRate scheduleFile `json:"learningRate"`
+	Temperature  scheduleFile `json:"temperature"`
+	Credit       creditFile   `json:"credit"`
+	Workers      int          `json:"workers,omitempty"`
+	BatchGames   int          `json:"batchGames,omitempty"`
+}
+
+// scheduleFile is the persisted form of a schedule.
+type scheduleFile struct {
+	Decay  string  `json:"decay"`
+	Start  float64 `json:"start"`
+	End    float64 `json:"end,omitempty"`
+	Factor float64 `json:"factor,omitempty"`
+	Period int     `json:"period,omitempty"`
+}
+
+// creditFile is the persisted form of a credit config.
+type creditFile struct {
+	Discount  float64 `json:"discount"`
+	Win       float64 `json:"win"`
+	Loss      float64 `json:"loss"`
+	Stalemate float64 `json:"stalemate"`
+	AllMoves  bool    `json:"allMoves,omitempty"`
+}
+
+// positionFile is the persisted form of a position. Each row of the board is a
+// string of pawns from top to bottom.
+type positionFile struct {
+	Turn     string        `json:"turn"`
+	Board    []string      `json:"board"`
+	PawnOpts []pawnOptFile `json:"pawnOpts"`
+}
+
+// pawnOptFile is the persisted form of a pawn option.
+type pawnOptFile struct {
+	Row    int     `json:"row"`
+	Column int     `json:"column"`
+	Action action  `json:"action"`
+	Weight float64 `json:"weight"`
+}
+
+// saveAutoPlayer writes an auto player to a file.
+func saveAutoPlayer(ap *autoPlayer, path string) error {
+	if err := newAgentFile(ap).write(path); err != nil {
+		return fmt.Errorf("saveAutoPlayer: %v", err)
+	}
+
+	return nil
+}
+
+// loadAutoPlayer reads an auto player from a file.
+func loadAutoPlayer(path string) (*autoPlayer, error) {
+	af, err := readAgentFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loadAutoPlayer: %v", err)
+	}
+
+	ap, err := af.autoPlayer()
+	if err != nil {
+		return nil, fmt.Errorf("loadAutoPlayer: %v", err)
+	}
+
+	return ap, nil
+}
+
+// newAgentFile returns the persisted form of an auto player.
+func newAgentFile(ap *autoPlayer) *agentFile {
+	af := &agentFile{
+		Side:        string(ap.sd),
+		Rows:        ap.m,
+		Columns:     ap.n,
+		Seed:        ap.seed,
+		Temperature: &ap.temp,
+		Sessions:    make([]sessionFile, 0, len(ap.sessions)),
+		Positions:   make([]positionFile, 0, len(ap.psns)),
+	}
+
+	for _, cfg := range ap.sessions {
+		af.Sessions = append(af.Sessions, newSessionFile(cfg))
+	}
+
+	for _, psn := range ap.sorted() {
+		pf := positionFile{
+			Turn:     string(sideOf(psn.st)),
+			Board:    make([]string, 0, len(psn.brd)),
+			PawnOpts: make([]pawnOptFile, 0, len(psn.pos)),
+		}
+
+		for i := range psn.brd {
+			pf.Board = append(pf.Board, string(psn.brd[i]))
+		}
+
+		for _, po := range psn.pos {
+			pf.PawnOpts = append(pf.PawnOpts, pawnOptFile{Row: po.m, Column: po.n, Action: po.act, Weight: float64(po.wght)})
+		}
+
+		af.Positions = append(af.Positions, pf)
+	}
+
+	return af
+}
+
+// write an agent file to a path. The file is written to a temporary path first
+// and then renamed, so an interrupted write never leaves a partial file behind.
+func (af *agentFile) write(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(af); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// readAgentFile reads an agent file from a path.
+func readAgentFile(path string) (*agentFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var af agentFile
+	if err := json.NewDecoder(f).Decode(&af); err != nil {
+		return nil, err
+	}
+
+	return &af, nil
+}
+
+// autoPlayer returns the auto player an agent file represents.
+func (af *agentFile) autoPlayer() (*autoPlayer, error) {
+	if af.Side != string(whiteSide) && af.Side != string(blackSide) {
+		return nil, fmt.Errorf("invalid side %q", af.Side)
+	}
+
+	if af.Rows < 3 || af.Columns < 3 {
+		return nil, fmt.Errorf("invalid dimensions %dx%d", af.Rows, af.Columns)
+	}
+
+	ap := newAutoPlayer(side(af.Side[0]), af.Rows, af.Columns, af.Seed)
+	if af.Temperature != nil {
+		ap.temp = *af.Temperature
+	}
+
+	for i, sf := range af.Sessions {
+		cfg, err := sf.trainConfig()
+		if err != nil {
+			return nil, fmt.Errorf("session %d: %v", i, err)
+		}
+
+		ap.sessions = append(ap.sessions, cfg)
+	}
+
+	for i, pf := range af.Positions {
+		psn, err := pf.position(af.Rows, af.Columns)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: %v", i, err)
+		}
+
+		ap.add(psn)
+	}
+
+	return ap, nil
+}
+
+// position returns the position a persisted position represents on an m-by-n
+// board.
+func (pf *positionFile) position(m, n int) (*position, error) {
+	psn := &position{brd: make(board, 0, m), pos: make(pawnOpts, 0, len(pf.PawnOpts))}
+	switch pf.Turn {
+	case string(whiteSide):
+		psn.st = whiteTurn
+	case string(blackSide):
+		psn.st = blackTurn
+	default:
+		return nil, fmt.Errorf("invalid turn %q", pf.Turn)
+	}
+
+	if len(pf.Board) != m {
+		return nil, fmt.Errorf("expected %d rows, got %d", m, len(pf.Board))
+	}
+
+	for _, row := range pf.Board {
+		if len(row) != n {
+			return nil, fmt.Errorf("expected %d columns, got %d", n, len(row))
+		}
+
+		for _, p := range []byte(row) {
+			if p != byte(space) && p != byte(whitePawn) && p != byte(blackPawn) {
+				return nil, fmt.Errorf("invalid pawn %q", p)
+			}
+		}
+
+		psn.brd = append(psn.brd, []pawn(row))
+	}
+
+	for _, pof := range pf.PawnOpts {
+		if pof.Row < 0 || m <= pof.Row || pof.Column < 0 || n <= pof.Column || captureRight < pof.Action {
+			return nil, fmt.Errorf("invalid pawn option at (%d,%d)", pof.Row, pof.Column)
+		}
+
+		psn.pos = append(psn.pos, &pawnOpt{m: pof.Row, n: pof.Column, act: pof.Action, wght: weight(pof.Weight)})
+	}
+
+	sort.Slice(psn.pos, psn.pos.less)
+	return psn, nil
+}
+
+// newSessionFile returns the persisted form of a train config.
+func newSessionFile(cfg trainConfig) sessionFile {
+	return sessionFile{
+		Games:        cfg.numGames,
+		Seed:         cfg.seed,
+		LearningRate: newScheduleFile(cfg.learningRate),
+		Temperature:  newScheduleFile(cfg.temperature),
+		Credit: creditFile{
+			Discount:  float64(cfg.credit.discount),
+			Win:       float64(cfg.credit.winReward),
+			Loss:      float64(cfg.credit.lossReward),
+			Stalemate: float64(cfg.credit.stalemateReward),
+			AllMoves:  cfg.credit.allMoves,
+		},
+		Workers:    cfg.workers,
+		BatchGames: cfg.batchGames,
+	}
+}
+
+// trainConfig returns the train config a persisted session represents.
+func (sf *sessionFile) trainConfig() (trainConfig, error) {
+	lr, err := sf.LearningRate.schedule()
+	if err != nil {
+		return trainConfig{}, fmt.Errorf("learning rate: %v", err)
+	}
+
+	temp, err := sf.Temperature.schedule()
+	if err != nil {
+		return trainConfig{}, fmt.Errorf("temperature: %v", err)
+	}
+
+	return trainConfig{
+		numGames:     sf.Games,
+		seed:         sf.Seed,
+		learningRate: lr,
+		temperature:  temp,
+		credit: creditConfig{
+			discount:        weight(sf.Credit.Discount),
+			winReward:       weight(sf.Credit.Win),
+			lossReward:      weight(sf.Credit.Loss),
+			stalemateReward: weight(sf.Credit.Stalemate),
+			allMoves:        sf.Credit.AllMoves,
+		},
+		workers:    sf.Workers,
+		batchGames: sf.BatchGames,
+	}, nil
+}
+
+// newScheduleFile returns the persisted form of a schedule.
+func newScheduleFile(sch schedule) scheduleFile {
+	return scheduleFile{
+		Decay:  decayNames[sch.dcy],
+		Start:  sch.start,
+		End:    sch.end,
+		Factor: sch.factor,
+		Period: sch.period,
+	}
+}
+
+// schedule returns the schedule a persisted schedule represents.
+func (sf *scheduleFile) schedule() (schedule, error) {
+	dcy, err := parseDecay(sf.Decay)
+	if err != nil {
+		return schedule{}, err
+	}
+
+	sch := schedule{dcy: dcy, start: sf.Start, end: sf.End, factor: sf.Factor, period: sf.Period}
+	if err := sch.validate(); err != nil {
+		return schedule{}, err
+	}
+
+	return sch, nil
+}
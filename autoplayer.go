@@ -2,7 +2,7 @@ package main
 
 import (
 	"fmt"
-	"log"
+	"math"
 	"math/rand"
 	"sort"
 	"strings"
@@ -18,10 +18,26 @@ import (
 // autoPlayer is an assigned side with a set of positions trained on to play
 // hexapawn. An auto player can only play on mxn boards.
 type autoPlayer struct {
-	sd   side        // White or black side
-	m    int         // Number of rows
-	n    int         // Number of columns
-	psns []*position // Set of positions experienced
+	sd       side           // White or black side
+	m        int            // Number of rows
+	n        int            // Number of columns
+	temp     float64        // Exploration temperature; one samples weights as is and zero always selects the highest weight
+	seed     int64          // Seed the auto player was created with
+	rnd      *rand.Rand     // Source of random selections
+	psns     []*position    // Set of positions experienced, in the order experienced
+	keys     map[uint64]int // Index of each position in psns by its zobrist hash
+	sessions []trainConfig  // Training sessions applied, in order
+}
+
+// trainConfig determines how an auto player is trained over a session of games.
+type trainConfig struct {
+	numGames     int          // Number of games to play
+	seed         int64        // Seed of the random sources of the players in each game
+	learningRate schedule     // Amount to alter weights by after each game
+	temperature  schedule     // Exploration temperature of each game
+	credit       creditConfig // Determines how the outcome of each game is credited
+	workers      int          // Number of games played concurrently; fewer than two trains serially
+	batchGames   int          // Number of games per batch when training in parallel; zero chooses by the number of workers
 }
 
 // String returns a formated representation of an autoplayer.
@@ -29,15 +45,16 @@ func (ap *autoPlayer) String() string {
 	bldr := strings.Builder{}
 
 	bldr.WriteString(fmt.Sprintf("side: %q\n", byte(ap.sd)))
-	for i := range ap.psns {
-		bldr.WriteString(ap.psns[i].String())
+	for _, psn := range ap.sorted() {
+		bldr.WriteString(psn.String())
 	}
 
 	return bldr.String()
 }
 
-// newAutoPlayer returns an autoPlayer associated with a side.
-func newAutoPlayer(sd side, m, n int) *autoPlayer {
+// newAutoPlayer returns an autoPlayer associated with a side and a random source
+// seeded with a seed.
+func newAutoPlayer(sd side, m, n int, seed int64) *autoPlayer {
 	if sd != whiteSide && sd != blackSide {
 		panic("newAutoPlayer: invalid side")
 	}
@@ -46,184 +63,124 @@ func newAutoPlayer(sd side, m, n int) *autoPlayer {
 		panic("newAutoPlayer: invalid dimensions")
 	}
 
-	return &autoPlayer{sd: sd, m: m, n: n, psns: make([]*position, 0, 32)}
+	return &autoPlayer{sd: sd, m: m, n: n, temp: 1, seed: seed, rnd: newRand(seed), psns: make([]*position, 0, 32), keys: make(map[uint64]int)}
+}
+
+// clone returns a copy of an auto player.
+func (ap *autoPlayer) clone() player {
+	return copyAutoPlayer(ap)
+}
+
+// reseed an auto player's random source.
+func (ap *autoPlayer) reseed(seed int64) {
+	ap.rnd = newRand(seed)
 }
 
-// train an auto player on a number of random games.
+// train an auto player on a number of random games. The games are seeded by the
+// auto player's seed.
 func (ap *autoPlayer) train(numGames int, learningRate weight) {
-	var (
-		gameOver   bool      // Indicates game was won/lost
-		index      int       // Index of position in auto player
-		apPosLen   int       // Number of pawn options in the indexed position of auto player
-		punishment weight    // Amount to alter non-selected pawn options' weights
-		gm         *game     // Game to be played for a given number of games
-		psn        *position // Current position of the game
-	)
+	ap.trainAgainst(newRandomPlayer(ap.seed), trainConfig{
+		numGames:     numGames,
+		seed:         ap.seed,
+		learningRate: constantSchedule(float64(learningRate)),
+		temperature:  constantSchedule(1),
+		credit:       defaultCreditConfig(),
+	})
+}
 
-	for k := 0; k < numGames; k++ {
-		gm = newGame(ap.m, ap.n, cvc)
-		gameOver = false
+// trainAgainst trains an auto player on a number of games played against an
+// opponent. The opponent's weights are not altered, so it may be a random
+// player, a search player, or a fixed, previously trained auto player.
+func (ap *autoPlayer) trainAgainst(opp player, cfg trainConfig) {
+	cfg.mustValidate()
+	ap.trainGames(opp, cfg, 0, cfg.numGames)
+	ap.sessions = append(ap.sessions, cfg)
+}
 
-		// Alternate turns until neither side can move (that is, win, illegal, or stalemate state is reached)
-		for !gameOver {
-			psn = &position{
-				brd: gm.brd,
-				st:  gm.st,
-				pos: availPawnOpts(gm.brd, gm.st),
-			}
+// trainGames trains an auto player on the games of a session numbered from k0 up
+// to, but not including, k1. The session is not recorded. Both players are
+// reseeded from the session's seed and k0, so training a session in parts
+// produces the same auto player as training it at once in the same parts. An
+// opponent that is an auto player is played as a fixed player and not altered.
+func (ap *autoPlayer) trainGames(opp player, cfg trainConfig, k0, k1 int) {
+	opp = fixedPlayer(opp)
+	if 1 < cfg.workers {
+		ap.trainParallel(opp, cfg, k0, k1)
+		return
+	}
 
-			switch gm.st {
-			case whiteTurn:
-				switch ap.sd {
-				case whiteSide:
-					gm.move(ap.chooseEvent(psn))
-				case blackSide:
-					gm.move(&event{psn: copyPosition(psn), poSlc: randPawnOpt(psn)})
-				}
-			case blackTurn:
-				switch ap.sd {
-				case whiteSide:
-					gm.move(&event{psn: copyPosition(psn), poSlc: randPawnOpt(psn)})
-				case blackSide:
-					gm.move(ap.chooseEvent(psn))
-				}
-			case whiteWin:
-				switch ap.sd {
-				case whiteSide:
-					for _, evnt := range gm.hst {
-						index = ap.index(evnt.psn)
-						if index < 0 {
-							continue
-						}
-
-						apPosLen = len(ap.psns[index].pos)
-						if apPosLen < 2 {
-							continue // Either zero or one pawn option to select; nothing to train on
-						}
-
-						punishment = learningRate / weight(apPosLen-1)
-						for i := range ap.psns[index].pos {
-							if equalPawnOpts(ap.psns[index].pos[i], evnt.poSlc) {
-								ap.psns[index].pos[i].wght += learningRate
-								continue
-							}
-
-							ap.psns[index].pos[i].wght -= punishment
-						}
-					}
-				case blackSide:
-					for _, evnt := range gm.hst {
-						index = ap.index(evnt.psn)
-						if index < 0 {
-							continue
-						}
-
-						apPosLen = len(ap.psns[index].pos)
-						if apPosLen < 2 {
-							continue // Either zero or one pawn option to select; nothing to train on
-						}
-
-						punishment = learningRate / weight(apPosLen-1)
-						for i := range ap.psns[index].pos {
-							if equalPawnOpts(ap.psns[index].pos[i], evnt.poSlc) {
-								ap.psns[index].pos[i].wght -= learningRate
-								continue
-							}
-
-							ap.psns[index].pos[i].wght += punishment
-						}
-					}
-				}
+	var white, black player = ap, opp
+	if ap.sd == blackSide {
+		white, black = opp, ap
+	}
 
-				gameOver = true
-			case blackWin:
-				switch ap.sd {
-				case whiteSide:
-					for _, evnt := range gm.hst {
-						index = ap.index(evnt.psn)
-						if index < 0 {
-							continue
-						}
-
-						apPosLen = len(ap.psns[index].pos)
-						if apPosLen < 2 {
-							continue // Either zero or one pawn option to select; nothing to train on
-						}
-
-						punishment = learningRate / weight(apPosLen-1)
-						for i := range ap.psns[index].pos {
-							if equalPawnOpts(ap.psns[index].pos[i], evnt.poSlc) {
-								ap.psns[index].pos[i].wght -= learningRate
-								continue
-							}
-
-							ap.psns[index].pos[i].wght += punishment
-						}
-					}
-				case blackSide:
-					for _, evnt := range gm.hst {
-						index = ap.index(evnt.psn)
-						if index < 0 {
-							continue
-						}
-
-						apPosLen = len(ap.psns[index].pos)
-						if apPosLen < 2 {
-							continue // Either zero or one pawn option to select; nothing to train on
-						}
-
-						punishment = learningRate / weight(apPosLen-1)
-						for i := range ap.psns[index].pos {
-							if equalPawnOpts(ap.psns[index].pos[i], evnt.poSlc) {
-								ap.psns[index].pos[i].wght += learningRate
-								continue
-							}
-
-							ap.psns[index].pos[i].wght -= punishment
-						}
-					}
-				}
+	ap.reseed(deriveSeed(cfg.seed, int64(k0), 0))
+	reseedPlayer(opp, deriveSeed(cfg.seed, int64(k0), 1))
 
-				gameOver = true
-			case stalemate:
-				for _, evnt := range gm.hst {
-					index = ap.index(evnt.psn)
-					if index < 0 {
-						continue
-					}
-
-					apPosLen = len(ap.psns[index].pos)
-					if evnt.poSlc == nil || apPosLen < 2 {
-						continue // Either zero (stalemate) or one pawn option to select; nothing to train on
-					}
-
-					punishment = learningRate / weight(apPosLen-1)
-					for i := range ap.psns[index].pos {
-						if equalPawnOpts(ap.psns[index].pos[i], evnt.poSlc) {
-							ap.psns[index].pos[i].wght -= learningRate
-							continue
-						}
-
-						ap.psns[index].pos[i].wght += punishment
-					}
-				}
+	temp := ap.temp
+	for k := k0; k < k1; k++ {
+		ap.temp = cfg.temperature.value(k, cfg.numGames)
+		gm := playGame(white, black, ap.m, ap.n)
+		ap.learn(gm.hst, gm.st, ap.sd, weight(cfg.learningRate.value(k, cfg.numGames)), cfg.credit)
+	}
 
-				gameOver = true
-			case illegal:
-				log.Fatal("train: reached illegal state")
-			default:
-				log.Fatal("train: reached unknown state")
-			}
+	ap.temp = temp
+}
+
+// mustValidate panics if a train config's schedules are invalid.
+func (cfg trainConfig) mustValidate() {
+	if err := cfg.learningRate.validate(); err != nil {
+		panic("mustValidate: learning rate: " + err.Error())
+	}
+
+	if err := cfg.temperature.validate(); err != nil {
+		panic("mustValidate: temperature: " + err.Error())
+	}
+}
+
+// learn adjusts the weights of the pawn options selected by a side in a history
+// of events given the state the game ended in. Each selected pawn option is
+// credited with the reward for the outcome, discounted by the number of credited
+// pawn options selected after it.
+func (ap *autoPlayer) learn(hst history, st state, sd side, learningRate weight, cc creditConfig) {
+	creditEvents(hst, st, sd, learningRate, cc, func(evnt *event, credit weight) {
+		if index := ap.index(evnt.psn); 0 <= index {
+			ap.psns[index].credit(evnt.poSlc, credit)
+		}
+	})
+}
+
+// creditEvents calls a function with each event in a history that is credited
+// for the state the game ended in and the amount it is credited. The discount is
+// applied once for each credited event after an event, so neither a final
+// stalemate nor moves that are not credited change it.
+func creditEvents(hst history, st state, sd side, learningRate weight, cc creditConfig, f func(evnt *event, credit weight)) {
+	var (
+		reward   = cc.reward(st, sd)
+		discount = weight(1) // Discount applied to the next credited event
+	)
+
+	for i := len(hst) - 1; 0 <= i; i-- {
+		evnt := hst[i]
+		if evnt.poSlc == nil {
+			continue // Stalemate; no pawn option was selected
 		}
+
+		if !cc.allMoves && evnt.psn.st != turnOf(sd) {
+			continue // Not a move made by the side being trained
+		}
+
+		f(evnt, learningRate*reward*discount)
+		discount *= cc.discount
 	}
 }
 
 // randPawnOpt returns a random pawn option at a given position (nil if none
 // available).
-func randPawnOpt(psn *position) *pawnOpt {
+func randPawnOpt(psn *position, rnd *rand.Rand) *pawnOpt {
 	n := len(psn.pos)
 	if 0 < n {
-		return psn.pos[rand.Intn(n)]
+		return psn.pos[rnd.Intn(n)]
 	}
 
 	return nil
@@ -238,63 +195,159 @@ func (ap *autoPlayer) chooseEvent(psn *position) *event {
 		index = ap.insert(psn)
 	}
 
-	choice := weight(rand.Float64())
-	var sum weight
-	for _, po := range ap.psns[index].pos {
-		if po.wght < 0 {
-			continue
+	if po := choosePawnOpt(ap.psns[index].pos, ap.temp, ap.rnd); po != nil {
+		return &event{psn: copyPosition(psn), poSlc: copyPawnOpt(po)}
+	}
+
+	return &event{psn: copyPosition(psn)} // TODO: determine if this should panic here
+}
+
+// choosePawnOpt returns a pawn option selected at random given an exploration
+// temperature. Each pawn option with a positive weight w is selected with
+// probability proportional to w^(1/temp), so a temperature above one explores
+// more and a temperature below one exploits more. A temperature of zero always
+// selects the first pawn option of highest weight. Nil is returned if no pawn
+// option can be selected.
+func choosePawnOpt(pos pawnOpts, temp float64, rnd *rand.Rand) *pawnOpt {
+	switch {
+	case temp <= 0:
+		var best *pawnOpt
+		for _, po := range pos {
+			if best == nil || best.wght < po.wght {
+				best = po
+			}
+		}
+
+		return best
+	case temp == 1:
+		choice := weight(rnd.Float64())
+		var sum weight
+		for _, po := range pos {
+			if po.wght < 0 {
+				continue
+			}
+
+			sum += po.wght
+			if choice <= sum {
+				return po
+			}
 		}
 
-		sum += po.wght
-		if choice <= sum {
-			return &event{psn: copyPosition(psn), poSlc: copyPawnOpt(po)}
+		return nil
+	default:
+		var total float64
+		for _, po := range pos {
+			if 0 < po.wght {
+				total += math.Pow(float64(po.wght), 1/temp)
+			}
+		}
+
+		choice := rnd.Float64() * total
+		var sum float64
+		for _, po := range pos {
+			if 0 < po.wght {
+				sum += math.Pow(float64(po.wght), 1/temp)
+				if choice <= sum {
+					return po
+				}
+			}
 		}
+
+		return nil
 	}
+}
 
-	return &event{psn: copyPosition(psn)} // TODO: determine if this should panic here
+// policy returns the probability choosePawnOpt selects each pawn option given an
+// exploration temperature and the probability it selects none.
+func policy(pos pawnOpts, temp float64) ([]float64, float64) {
+	probs := make([]float64, len(pos))
+	switch {
+	case temp <= 0:
+		best := -1
+		for i, po := range pos {
+			if best < 0 || pos[best].wght < po.wght {
+				best = i
+			}
+		}
+
+		if best < 0 {
+			return probs, 1
+		}
+
+		probs[best] = 1
+		return probs, 0
+	case temp == 1:
+		var sum float64 // Probability of selecting any pawn option so far
+		for i, po := range pos {
+			if po.wght < 0 {
+				continue
+			}
+
+			next := math.Min(sum+float64(po.wght), 1)
+			probs[i], sum = next-sum, next
+		}
+
+		return probs, 1 - sum
+	default:
+		var total float64
+		for i, po := range pos {
+			if 0 < po.wght {
+				probs[i] = math.Pow(float64(po.wght), 1/temp)
+				total += probs[i]
+			}
+		}
+
+		if total == 0 {
+			return probs, 1
+		}
+
+		for i := range probs {
+			probs[i] /= total
+		}
+
+		return probs, 0
+	}
 }
 
-// insert a position into an auto player and returns the position it is found in
-// after sorting.
+// insert a copy of a position into an auto player and return the index it is
+// found in. Positions are indexed in the order they are inserted.
 func (ap *autoPlayer) insert(psn *position) int {
-	ap.psns = append(ap.psns, copyPosition(psn))
-	sort.SliceStable(ap.psns, ap.less)
-	return ap.index(psn)
+	return ap.add(copyPosition(psn))
+}
+
+// add a position into an auto player without copying it and return the index it
+// is found in.
+func (ap *autoPlayer) add(psn *position) int {
+	ap.keys[psn.key()] = len(ap.psns)
+	ap.psns = append(ap.psns, psn)
+	return len(ap.psns) - 1
 }
 
 // remove a position from an auto player's experience.
 func (ap *autoPlayer) remove(i int) *position {
 	psn := ap.psns[i]
 	ap.psns = append(ap.psns[:i], ap.psns[i+1:]...)
+	delete(ap.keys, psn.key())
+	for ; i < len(ap.psns); i++ {
+		ap.keys[ap.psns[i].key()] = i
+	}
+
 	return psn
 }
 
 // index returns the index a position is found in an auto player. If the position
 // is not found, -1 is returned.
 func (ap *autoPlayer) index(psn *position) int {
-	n := len(ap.psns)
-	index := sort.Search(n, func(i int) bool { return lessEqPositions(psn, ap.psns[i]) })
-	if index < n && equalPositions(psn, ap.psns[index]) {
+	if index, ok := ap.keys[psn.key()]; ok {
 		return index
 	}
 
 	return -1
 }
 
-// less compares two indexed positions.
-func (ap *autoPlayer) less(i, j int) bool {
-	if comparePositions(ap.psns[i], ap.psns[j]) < 0 {
-		return true
-	}
-
-	return false
-}
-
-// lessEq compares two indexed positions.
-func (ap *autoPlayer) lessEq(i, j int) bool {
-	if 0 < comparePositions(ap.psns[i], ap.psns[j]) {
-		return false
-	}
-
-	return true
+// sorted returns the positions of an auto player sorted by state and then board.
+func (ap *autoPlayer) sorted() []*position {
+	psns := append(make([]*position, 0, len(ap.psns)), ap.psns...)
+	sort.Slice(psns, func(i, j int) bool { return lessPositions(psns[i], psns[j]) })
+	return psns
 }
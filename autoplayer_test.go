@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+// TestFixedOpponent checks that training against an auto player, serially or in
+// parallel, leaves the opponent's positions and weights unaltered.
+func TestFixedOpponent(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		opp := newAutoPlayer(blackSide, 4, 4, 1)
+		opp.train(200, 0.1)
+		before := copyAutoPlayer(opp)
+
+		ap := newAutoPlayer(whiteSide, 4, 4, 2)
+		ap.trainAgainst(opp, trainConfig{
+			numGames:     500,
+			seed:         3,
+			workers:      workers,
+			learningRate: constantSchedule(0.1),
+			temperature:  constantSchedule(1),
+			credit:       defaultCreditConfig(),
+		})
+
+		if len(opp.psns) != len(before.psns) {
+			t.Fatalf("%d workers: opponent grew from %d to %d positions", workers, len(before.psns), len(opp.psns))
+		}
+
+		for i, psn := range opp.psns {
+			for j, po := range psn.pos {
+				if po.wght != before.psns[i].pos[j].wght {
+					t.Fatalf("%d workers: opponent weights changed at\n%v", workers, psn.brd)
+				}
+			}
+		}
+	}
+}
+
+// TestEvalMatch checks that evaluating an auto player leaves its positions and
+// weights unaltered.
+func TestEvalMatch(t *testing.T) {
+	ap := newAutoPlayer(whiteSide, 4, 4, 1)
+	ap.train(100, 0.1)
+	before := copyAutoPlayer(ap)
+
+	if tly := ap.evalMatch(newRandomPlayer(0), 200, 2); tly.games() != 200 {
+		t.Fatalf("expected 200 evaluation games, got %d", tly.games())
+	}
+
+	if !equalAgents(ap, before) {
+		t.Errorf("evaluation grew the auto player from %d to %d positions or changed its weights", len(before.psns), len(ap.psns))
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// BenchmarkMovegen measures counting the leaves of game trees by generating pawn
+// options by scanning every square of the board, by walking the pawn lists of
+// the side to move, and by applying pawn options to boards and bitboards.
+func BenchmarkMovegen(b *testing.B) {
+	gens := []struct {
+		name string
+		gen  func(gm *game) pawnOpts
+	}{
+		{name: "scan", gen: func(gm *game) pawnOpts { return availPawnOpts(gm.brd, gm.st) }},
+		{name: "lists", gen: func(gm *game) pawnOpts { return gm.pawnOpts() }},
+	}
+
+	for _, d := range [][3]int{{6, 6, 5}, {8, 8, 4}} {
+		m, n, depth := d[0], d[1], d[2]
+		for _, g := range gens {
+			b.Run(fmt.Sprintf("%dx%d/%s", m, n, g.name), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					perftWith(newGame(m, n, cvc), depth, g.gen)
+				}
+			})
+		}
+
+		b.Run(fmt.Sprintf("%dx%d/board", m, n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				perftGrid(newBoard(m, n), whiteTurn, depth)
+			}
+		})
+
+		b.Run(fmt.Sprintf("%dx%d/bitboard", m, n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				perftGrid(toBitboard(newBoard(m, n)), whiteTurn, depth)
+			}
+		})
+	}
+}
+
+// BenchmarkTrain measures training an auto player against a random player
+// serially and with several workers.
+func BenchmarkTrain(b *testing.B) {
+	for _, workers := range []int{1, 2, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			cfg := trainConfig{
+				numGames:     2000,
+				seed:         1,
+				workers:      workers,
+				learningRate: constantSchedule(0.1),
+				temperature:  constantSchedule(1),
+				credit:       defaultCreditConfig(),
+			}
+
+			for i := 0; i < b.N; i++ {
+				newAutoPlayer(whiteSide, 4, 4, 1).trainAgainst(newRandomPlayer(1), cfg)
+			}
+		})
+	}
+}
+
+// BenchmarkStore measures looking up and inserting the positions of random games
+// in a sorted slice of positions and in an auto player's map of positions.
+func BenchmarkStore(b *testing.B) {
+	var (
+		rp   = newRandomPlayer(1)
+		psns = make([]*position, 0, 1024) // Positions of each game in the order reached
+	)
+
+	for k := 0; k < 200; k++ {
+		for _, evnt := range playGame(rp, rp, 4, 4).hst {
+			psns = append(psns, evnt.psn)
+		}
+	}
+
+	b.Run("sorted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var sps sortedPositions
+			for _, psn := range psns {
+				if sps.index(psn) < 0 {
+					sps.insert(psn)
+				}
+			}
+		}
+	})
+
+	b.Run("map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ap := newAutoPlayer(whiteSide, 4, 4, 1)
+			for _, psn := range psns {
+				if ap.index(psn) < 0 {
+					ap.insert(psn)
+				}
+			}
+		}
+	})
+}
+
+// sortedPositions is a set of positions kept sorted by state and then board, as
+// auto players kept their positions before indexing them by board key. It is
+// benchmarked against an auto player's map of positions.
+type sortedPositions []*position
+
+// insert a copy of a position and return the index it is found in after
+// sorting.
+func (psns *sortedPositions) insert(psn *position) int {
+	*psns = append(*psns, copyPosition(psn))
+	sort.SliceStable(*psns, func(i, j int) bool { return lessPositions((*psns)[i], (*psns)[j]) })
+	return psns.index(psn)
+}
+
+// index returns the index a position is found in. If the position is not
+// found, -1 is returned.
+func (psns sortedPositions) index(psn *position) int {
+	n := len(psns)
+	index := sort.Search(n, func(i int) bool { return lessEqPositions(psn, psns[i]) })
+	if index < n && equalPositions(psn, psns[index]) {
+		return index
+	}
+
+	return -1
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"math/bits"
+)
+
+// maxBitboardSquares is the greatest number of squares a bitboard can hold.
+const maxBitboardSquares = 64
+
+// bitboard is a board of at most 64 squares represented by a mask of the
+// squares holding pawns of each side. The square at row i and column j of an
+// m-by-n board is bit i*n+j, so moving a pawn forward one row shifts it by n
+// bits. Boards are converted to bitboards for fast move generation and win
+// detection, and bitboards are converted back to boards for display.
+type bitboard struct {
+	m     int    // Number of rows
+	n     int    // Number of columns
+	white uint64 // Squares holding white pawns
+	black uint64 // Squares holding black pawns
+}
+
+// fitsBitboard returns true if an m-by-n board can be represented by a bitboard.
+func fitsBitboard(m, n int) bool {
+	return m*n <= maxBitboardSquares
+}
+
+// newBitboard returns a new bitboard with black on top, white on bottom. Panics
+// if m or n are less than three or the board has more than 64 squares.
+func newBitboard(m, n int) bitboard {
+	return toBitboard(newBoard(m, n))
+}
+
+// toBitboard returns the bitboard representing a board. Panics if the board has
+// more than 64 squares.
+func toBitboard(brd board) bitboard {
+	bb := bitboard{m: len(brd), n: len(brd[0])}
+	if !fitsBitboard(bb.m, bb.n) {
+		panic("toBitboard: board has more than 64 squares")
+	}
+
+	for i := range brd {
+		for j, p := range brd[i] {
+			switch p {
+			case whitePawn:
+				bb.white |= 1 << uint(i*bb.n+j)
+			case blackPawn:
+				bb.black |= 1 << uint(i*bb.n+j)
+			}
+		}
+	}
+
+	return bb
+}
+
+// toBoard returns the board a bitboard represents.
+func (bb bitboard) toBoard() board {
+	brd := make(board, 0, bb.m)
+	for i := 0; i < bb.m; i++ {
+		row := make([]pawn, 0, bb.n)
+		for j := 0; j < bb.n; j++ {
+			switch bit := uint64(1) << uint(i*bb.n+j); {
+			case bb.white&bit != 0:
+				row = append(row, whitePawn)
+			case bb.black&bit != 0:
+				row = append(row, blackPawn)
+			default:
+				row = append(row, space)
+			}
+		}
+
+		brd = append(brd, row)
+	}
+
+	return brd
+}
+
+// String returns the formated representation of the board a bitboard
+// represents.
+func (bb bitboard) String() string {
+	return bb.toBoard().String()
+}
+
+// squares returns the mask of every square on a bitboard.
+func (bb bitboard) squares() uint64 {
+	if bb.m*bb.n == maxBitboardSquares {
+		return ^uint64(0)
+	}
+
+	return 1<<uint(bb.m*bb.n) - 1
+}
+
+// column returns the mask of every square in a column of a bitboard.
+func (bb bitboard) column(j int) uint64 {
+	var mask uint64
+	for i := 0; i < bb.m; i++ {
+		mask |= 1 << uint(i*bb.n+j)
+	}
+
+	return mask
+}
+
+// row returns the mask of every square in a row of a bitboard.
+func (bb bitboard) row(i int) uint64 {
+	return (1<<uint(bb.n) - 1) << uint(i*bb.n)
+}
+
+// targets returns the masks of the squares pawns of the side to move can move
+// forward to, capture left on, and capture right on, in that order, as seen
+// from the side to move. Each mask is indexed by the square the pawn moves to.
+func (bb bitboard) targets(st state) (uint64, uint64, uint64) {
+	var (
+		n     = uint(bb.n)
+		empty = bb.squares() &^ (bb.white | bb.black)
+		left  = bb.column(0)        // Squares a pawn cannot capture toward column zero from
+		right = bb.column(bb.n - 1) // Squares a pawn cannot capture toward the last column from
+	)
+
+	switch st {
+	case whiteTurn:
+		return bb.white >> n & empty, (bb.white &^ left) >> (n + 1) & bb.black, (bb.white &^ right) >> (n - 1) & bb.black
+	case blackTurn:
+		return bb.black << n & empty, (bb.black &^ right) << (n + 1) & bb.white, (bb.black &^ left) << (n - 1) & bb.white
+	default:
+		return 0, 0, 0
+	}
+}
+
+// origin returns the square a pawn of the side to move moved from to reach a
+// square by an action.
+func (bb bitboard) origin(sq int, act action, st state) int {
+	d := bb.n // Number of bits a forward move shifts by
+	switch act {
+	case captureLeft:
+		d++
+	case captureRight:
+		d--
+	}
+
+	if st == whiteTurn {
+		return sq + d
+	}
+
+	return sq - d
+}
+
+// pawnOpts returns the set of pawn options available at a bitboard and state,
+// in the same order and with the same weights as availPawnOpts.
+func (bb bitboard) pawnOpts(st state) pawnOpts {
+	var (
+		fwd, capL, capR = bb.targets(st)
+		movers          uint64 // Squares of pawns with an available action
+		acts            = [3]uint64{}
+	)
+
+	for act, mask := range [3]uint64{fwd, capL, capR} {
+		for ; mask != 0; mask &= mask - 1 {
+			sq := bb.origin(bits.TrailingZeros64(mask), action(act), st)
+			movers |= 1 << uint(sq)
+			acts[act] |= 1 << uint(sq)
+		}
+	}
+
+	pos := make(pawnOpts, 0, bits.OnesCount64(fwd)+bits.OnesCount64(capL)+bits.OnesCount64(capR))
+	for ; movers != 0; movers &= movers - 1 {
+		sq := bits.TrailingZeros64(movers)
+		for act := forward; act <= captureRight; act++ {
+			if acts[act]&(1<<uint(sq)) != 0 {
+				pos = append(pos, &pawnOpt{m: sq / bb.n, n: sq % bb.n, act: act})
+			}
+		}
+	}
+
+	wght := weight(1)
+	if 1 < len(pos) {
+		wght = 1 / weight(len(pos))
+	}
+
+	for _, po := range pos {
+		po.wght = wght
+	}
+
+	return pos
+}
+
+// move returns the bitboard and state reached by selecting a pawn option at a
+// bitboard and state. The pawn option is assumed to be available.
+func (bb bitboard) move(po *pawnOpt, st state) (bitboard, state) {
+	if po == nil {
+		return bb, stalemate // No pawn option selected is stalemate
+	}
+
+	from := uint64(1) << uint(po.m*bb.n+po.n)
+	i, j := po.target(st)
+	to := uint64(1) << uint(i*bb.n+j)
+	switch st {
+	case whiteTurn:
+		bb.white = bb.white&^from | to
+		bb.black &^= to
+		if bb.checkWin(st) {
+			return bb, whiteWin
+		}
+
+		return bb, blackTurn
+	case blackTurn:
+		bb.black = bb.black&^from | to
+		bb.white &^= to
+		if bb.checkWin(st) {
+			return bb, blackWin
+		}
+
+		return bb, whiteTurn
+	default:
+		panic("move: state is neither white nor black turn")
+	}
+}
+
+// checkWin checks a bitboard for a win condition given a state, as checkWin does
+// for boards.
+func (bb bitboard) checkWin(st state) bool {
+	switch st {
+	case whiteTurn:
+		return bb.white&bb.row(0) != 0 || bb.black&^bb.row(bb.m-1) == 0
+	case blackTurn:
+		return bb.black&bb.row(bb.m-1) != 0 || bb.white&^bb.row(0) == 0
+	default:
+		return false
+	}
+}
+
+// dims returns the number of rows and columns of a bitboard.
+func (bb bitboard) dims() (int, int) {
+	return bb.m, bb.n
+}
+
+// apply returns the bitboard and state reached by selecting a pawn option.
+func (bb bitboard) apply(po *pawnOpt, st state) (grid, state) {
+	return bb.move(po, st)
+}
@@ -0,0 +1,50 @@
+package main
+
+import "log"
+
+// creditConfig determines how the outcome of a game is credited to the pawn
+// options selected during it.
+type creditConfig struct {
+	discount        weight // Factor applied to the reward for each credited move made after an event
+	winReward       weight // Reward for the moves of the winning side
+	lossReward      weight // Reward for the moves of the losing side
+	stalemateReward weight // Reward for the moves of either side in a stalemate
+	allMoves        bool   // Credit the opponent's moves found in an auto player as though they were its own
+}
+
+// defaultCreditConfig returns a credit config that rewards wins, punishes losses
+// and stalemates equally, and does not discount rewards.
+func defaultCreditConfig() creditConfig {
+	return creditConfig{
+		discount:        1,
+		winReward:       1,
+		lossReward:      -1,
+		stalemateReward: -1,
+	}
+}
+
+// reward returns the reward given to a side for a game ending in a state.
+func (cc creditConfig) reward(st state, sd side) weight {
+	switch st {
+	case whiteWin:
+		if sd == whiteSide {
+			return cc.winReward
+		}
+
+		return cc.lossReward
+	case blackWin:
+		if sd == blackSide {
+			return cc.winReward
+		}
+
+		return cc.lossReward
+	case stalemate:
+		return cc.stalemateReward
+	case illegal:
+		log.Fatal("reward: reached illegal state")
+	default:
+		log.Fatal("reward: reached unknown state")
+	}
+
+	return 0
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCreditEvents checks that rewards are discounted once for each credited
+// move after a move, whether or not the game ended in stalemate and whether or
+// not the opponent's moves are credited.
+func TestCreditEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		psn      string   // Position the game begins at
+		moves    []string // Moves played
+		allMoves bool     // Credit the opponent's moves
+		want     []weight // Credit of each credited move, last first
+	}{
+		{name: "win", psn: "bbb/3/www w", moves: []string{"b1-b2", "a3-a2", "b2xc3"}, want: []weight{1, 0.5}},
+		{name: "stalemate", psn: "1b1/3/1w1 w", moves: []string{"b1-b2"}, want: []weight{-1}},
+		{name: "all moves", psn: "bbb/3/www w", moves: []string{"b1-b2", "a3-a2", "b2xc3"}, allMoves: true, want: []weight{1, 0.5, 0.25}},
+	}
+
+	for _, test := range tests {
+		brd, st, err := parsePosition(test.psn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		gm := newGameAt(brd, st, cvc)
+		for _, mv := range test.moves {
+			po, err := parseMove(mv, gm.brd, gm.st)
+			if err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+
+			gm.move(&event{psn: copyPosition(gm.position()), poSlc: copyPawnOpt(po)})
+		}
+
+		if gm.st == blackTurn && len(gm.pawnOpts()) == 0 {
+			gm.move(&event{psn: copyPosition(gm.position())}) // Stalemate
+		}
+
+		cc := defaultCreditConfig()
+		cc.discount, cc.allMoves = 0.5, test.allMoves
+
+		var got []weight
+		creditEvents(gm.hst, gm.st, whiteSide, 1, cc, func(evnt *event, credit weight) { got = append(got, credit) })
+		if len(got) != len(test.want) {
+			t.Fatalf("%s: expected %d credited moves, got %d", test.name, len(test.want), len(got))
+		}
+
+		for i := range got {
+			if 1e-9 < math.Abs(float64(got[i]-test.want[i])) {
+				t.Errorf("%s: expected credits %v, got %v", test.name, test.want, got)
+				break
+			}
+		}
+	}
+}
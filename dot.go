@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dotConfig determines which positions of a game tree are exported to Graphviz
+// DOT and how they are drawn.
+type dotConfig struct {
+	depth   int         // Number of plies exported
+	dag     bool        // Merge transpositions into a single node
+	slv     *solver     // Colors nodes by their solved outcome if not nil
+	ap      *autoPlayer // Labels the auto player's pawn options with its weights if not nil
+	visited bool        // Expand only the positions the auto player has experienced at its turn
+}
+
+// dotNode is a node of a game tree waiting to be written.
+type dotNode struct {
+	id    string // Name of the node in the graph
+	g     grid   // Pawns
+	st    state  // State
+	depth int    // Number of plies from the root
+}
+
+// Colors of nodes by solved outcome
+const (
+	whiteWinColor  = "lightblue"
+	blackWinColor  = "lightpink"
+	stalemateColor = "lightgray"
+)
+
+// writeDot writes the game tree from a board and state to a number of plies as a
+// Graphviz digraph. Nodes are labeled with the board and state, and edges with
+// the notation of the move and the weight of its pawn option. Positions are
+// written in breadth first order, so a transposition merged into a single node
+// is expanded from its shallowest occurrence.
+func writeDot(w io.Writer, brd board, st state, cfg dotConfig) error {
+	var (
+		bw    = bufio.NewWriter(w)
+		m     = len(brd)
+		ids   = make(map[uint64]string) // Names of the nodes written, by zobrist hash, when merging transpositions
+		queue = []dotNode{{id: "n0", g: newGrid(brd), st: st}}
+		count = 1 // Number of nodes named
+	)
+
+	// name returns the name of the node of a grid and state and whether it has
+	// been named before.
+	name := func(g grid, st state) (string, bool) {
+		if cfg.dag {
+			key := g.zobrist(st)
+			if id, ok := ids[key]; ok {
+				return id, true
+			}
+
+			ids[key] = fmt.Sprintf("n%d", count)
+			count++
+			return ids[key], false
+		}
+
+		count++
+		return fmt.Sprintf("n%d", count-1), false
+	}
+
+	if cfg.dag {
+		ids[queue[0].g.zobrist(st)] = queue[0].id
+	}
+
+	fmt.Fprintln(bw, "digraph hexapawn {")
+	fmt.Fprintln(bw, "\tnode [shape=box, fontname=\"Courier\", style=filled, fillcolor=white];")
+	for 0 < len(queue) {
+		nd := queue[0]
+		queue = queue[1:]
+
+		fmt.Fprintf(bw, "\t%s [label=\"%s\"%s];\n", nd.id, dotLabel(nd.g.toBoard(), nd.st), cfg.nodeAttrs(nd.g, nd.st))
+		if nd.st != whiteTurn && nd.st != blackTurn || nd.depth == cfg.depth {
+			continue
+		}
+
+		pos, ok := cfg.pawnOpts(nd.g, nd.st)
+		if !ok {
+			continue
+		}
+
+		if len(pos) == 0 {
+			id, seen := name(nd.g, stalemate)
+			fmt.Fprintf(bw, "\t%s -> %s [label=\"stalemate\"];\n", nd.id, id)
+			if !seen {
+				queue = append(queue, dotNode{id: id, g: nd.g, st: stalemate, depth: nd.depth + 1})
+			}
+
+			continue
+		}
+
+		for _, po := range pos {
+			child, childSt := nd.g.apply(po, nd.st)
+			id, seen := name(child, childSt)
+			fmt.Fprintf(bw, "\t%s -> %s [label=\"%s\\n%.3f\"];\n", nd.id, id, moveNotation(po, nd.st, m), po.wght)
+			if !seen {
+				queue = append(queue, dotNode{id: id, g: child, st: childSt, depth: nd.depth + 1})
+			}
+		}
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// pawnOpts returns the pawn options to export at a grid and state and whether
+// the position is expanded at all. At the auto player's turn, the auto
+// player's pawn options and weights are used if it has experienced the
+// position; otherwise the position is not expanded if only visited positions
+// are exported.
+func (cfg dotConfig) pawnOpts(g grid, st state) (pawnOpts, bool) {
+	if cfg.ap != nil && st == turnOf(cfg.ap.sd) {
+		if index := cfg.ap.index(&position{brd: g.toBoard(), st: st}); 0 <= index {
+			return cfg.ap.psns[index].pos, true
+		}
+
+		if cfg.visited {
+			return nil, false
+		}
+	}
+
+	return g.pawnOpts(st), true
+}
+
+// nodeAttrs returns the attributes of the node of a grid and state beyond its
+// label: a fill color by solved outcome if a solver is given, and a bold border
+// if the game is over.
+func (cfg dotConfig) nodeAttrs(g grid, st state) string {
+	var attrs string
+	if cfg.slv != nil {
+		res := draw // Result for white
+		switch st {
+		case whiteWin:
+			res = win
+		case blackWin:
+			res = loss
+		case whiteTurn:
+			res = cfg.slv.solve(g, st).res
+		case blackTurn:
+			res = win - cfg.slv.solve(g, st).res
+		}
+
+		switch res {
+		case win:
+			attrs += ", fillcolor=" + whiteWinColor
+		case loss:
+			attrs += ", fillcolor=" + blackWinColor
+		default:
+			attrs += ", fillcolor=" + stalemateColor
+		}
+	}
+
+	if st != whiteTurn && st != blackTurn {
+		attrs += ", penwidth=3"
+	}
+
+	return attrs
+}
+
+// dotLabel returns the label of a node: the rows of a board from black's side,
+// with a dot for each empty square, and the state.
+func dotLabel(brd board, st state) string {
+	rows := make([]string, 0, len(brd)+1)
+	for i := range brd {
+		rows = append(rows, strings.Replace(string(brd[i]), string(space), ".", -1))
+	}
+
+	return strings.Join(append(rows, stateName(st)), "\\n")
+}
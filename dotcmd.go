@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// dotCmd exports the game tree from a position to Graphviz DOT.
+func dotCmd(args []string) error {
+	var (
+		fs      = flag.NewFlagSet("dot", flag.ContinueOnError)
+		m       = fs.Int("m", 3, "number of rows")
+		n       = fs.Int("n", 3, "number of columns")
+		psn     = fs.String("position", "", "position in notation, such as \"bbb/3/www w\" (default the starting position)")
+		depth   = fs.Int("depth", 3, "number of plies exported")
+		dag     = fs.Bool("dag", false, "merge transpositions into a single node")
+		solve   = fs.Bool("solve", false, "color nodes by solved outcome")
+		agent   = fs.String("agent", "", "agent file whose weights label its moves")
+		visited = fs.Bool("visited", false, "expand only positions the agent has experienced at its turn")
+		out     = fs.String("out", "", "file the graph is written to (default standard output)")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *depth < 0 {
+		return fmt.Errorf("dot: invalid depth %d", *depth)
+	}
+
+	var (
+		brd board
+		st  = whiteTurn
+	)
+
+	if *psn == "" {
+		if *m < 3 || *n < 3 {
+			return fmt.Errorf("dot: invalid dimensions %dx%d", *m, *n)
+		}
+
+		brd = newBoard(*m, *n)
+	} else {
+		var err error
+		if brd, st, err = parsePosition(*psn); err != nil {
+			return fmt.Errorf("dot: %v", err)
+		}
+	}
+
+	cfg := dotConfig{depth: *depth, dag: *dag, visited: *visited}
+	if *solve {
+		cfg.slv = newSolver()
+	}
+
+	if *agent != "" {
+		ap, err := loadAutoPlayer(*agent)
+		if err != nil {
+			return fmt.Errorf("dot: %v", err)
+		}
+
+		if ap.m != len(brd) || ap.n != len(brd[0]) {
+			return fmt.Errorf("dot: %s plays on %dx%d boards, not %dx%d", *agent, ap.m, ap.n, len(brd), len(brd[0]))
+		}
+
+		cfg.ap = ap
+	} else if *visited {
+		return fmt.Errorf("dot: -visited requires an agent")
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("dot: %v", err)
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	if err := writeDot(w, brd, st, cfg); err != nil {
+		return fmt.Errorf("dot: %v", err)
+	}
+
+	return nil
+}
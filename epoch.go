@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// epochConfig determines how an auto player is trained in epochs. Each epoch is
+// a number of training games followed by an evaluation match played with the
+// auto player's greedy policy.
+type epochConfig struct {
+	trainConfig             // Training applied over every epoch
+	epochGames  int         // Number of training games per epoch
+	evalGames   int         // Number of evaluation games per epoch
+	opp         string      // Spec of the training opponent, as given to parsePlayer
+	evalOpp     string      // Spec of the opponent played in evaluation matches
+	checkpoint  string      // Path a checkpoint is written to after each epoch; empty disables checkpoints
+	target      float64     // Evaluation score at which training stops early; zero disables
+	patience    int         // Number of epochs without improvement after which training stops early; zero disables
+	minDelta    float64     // Least increase in evaluation score counted as an improvement
+	logger      *log.Logger // Logs the evaluation of each epoch; nil disables logging
+}
+
+// progress is the state of an auto player being trained in epochs.
+type progress struct {
+	gamesPlayed int     // Number of training games played so far
+	epoch       int     // Number of epochs completed so far
+	bestScore   float64 // Highest evaluation score so far
+	stale       int     // Number of epochs since the best evaluation score improved
+	stopped     bool    // Indicates training stopped early
+}
+
+// tally counts the outcomes of a number of games from one side's perspective.
+type tally struct {
+	wins       int // Number of games won
+	losses     int // Number of games lost
+	stalemates int // Number of games ending in stalemate
+}
+
+// String returns a formated representation of a tally.
+func (tly tally) String() string {
+	n := float64(tly.games())
+	if n == 0 {
+		return "no games"
+	}
+
+	return fmt.Sprintf("wins %.3f, losses %.3f, stalemates %.3f, score %.3f", float64(tly.wins)/n, float64(tly.losses)/n, float64(tly.stalemates)/n, tly.score())
+}
+
+// games returns the number of games counted.
+func (tly tally) games() int {
+	return tly.wins + tly.losses + tly.stalemates
+}
+
+// score returns the fraction of points earned, counting a win as one point and a
+// stalemate as half a point.
+func (tly tally) score() float64 {
+	n := tly.games()
+	if n == 0 {
+		return 0
+	}
+
+	return (float64(tly.wins) + float64(tly.stalemates)/2) / float64(n)
+}
+
+// add counts a game ending in a state from a side's perspective.
+func (tly *tally) add(st state, sd side) {
+	switch st {
+	case whiteWin, blackWin:
+		if (st == whiteWin) == (sd == whiteSide) {
+			tly.wins++
+		} else {
+			tly.losses++
+		}
+	case stalemate:
+		tly.stalemates++
+	default:
+		panic("add: game is not over")
+	}
+}
+
+// evalMatch plays a number of games against an opponent with an auto player's
+// greedy policy and returns the outcomes. The opponent is reseeded with a seed
+// and, if it is an auto player, played as a fixed player. The auto player is
+// played as a policy player, so neither its weights nor its positions are
+// altered.
+func (ap *autoPlayer) evalMatch(opp player, numGames int, seed int64) tally {
+	opp = fixedPlayer(opp)
+	reseedPlayer(opp, seed)
+
+	var white, black player = &policyPlayer{ap: ap, rnd: newRand(seed)}, opp
+	if ap.sd == blackSide {
+		white, black = opp, white
+	}
+
+	var tly tally
+	for k := 0; k < numGames; k++ {
+		tly.add(playGame(white, black, ap.m, ap.n).st, ap.sd)
+	}
+
+	return tly
+}
+
+// trainEpochs trains an auto player against an opponent in epochs, continuing
+// from a given progress, and returns the final progress. After each epoch, the
+// auto player is evaluated against an evaluation opponent and a checkpoint is
+// written. Training stops early if the evaluation score reaches the target or
+// stops improving. The session is recorded once training ends.
+func (ap *autoPlayer) trainEpochs(opp, evalOpp player, cfg epochConfig, prg progress) (progress, error) {
+	cfg.mustValidate()
+	if cfg.epochGames < 1 {
+		panic("trainEpochs: epochs must have at least one game")
+	}
+
+	for !prg.stopped && prg.gamesPlayed < cfg.numGames {
+		k1 := prg.gamesPlayed + cfg.epochGames
+		if cfg.numGames < k1 {
+			k1 = cfg.numGames
+		}
+
+		ap.trainGames(opp, cfg.trainConfig, prg.gamesPlayed, k1)
+		prg.gamesPlayed = k1
+		prg.epoch++
+
+		tly := ap.evalMatch(evalOpp, cfg.evalGames, deriveSeed(cfg.seed, int64(prg.epoch), 2))
+		score := tly.score()
+		if prg.epoch == 1 || prg.bestScore+cfg.minDelta < score {
+			prg.bestScore, prg.stale = score, 0
+		} else {
+			prg.stale++
+		}
+
+		if cfg.logger != nil {
+			cfg.logger.Printf("epoch %d: games %d, %s\n", prg.epoch, prg.gamesPlayed, tly)
+		}
+
+		switch {
+		case 0 < cfg.target && cfg.target <= score:
+			prg.stopped = true
+			if cfg.logger != nil {
+				cfg.logger.Printf("target score %.3f reached\n", cfg.target)
+			}
+		case 0 < cfg.patience && cfg.patience <= prg.stale:
+			prg.stopped = true
+			if cfg.logger != nil {
+				cfg.logger.Printf("no improvement in %d epochs\n", prg.stale)
+			}
+		}
+
+		if cfg.checkpoint != "" {
+			if err := writeCheckpoint(ap, cfg, prg, cfg.checkpoint); err != nil {
+				return prg, fmt.Errorf("trainEpochs: %v", err)
+			}
+		}
+	}
+
+	ap.sessions = append(ap.sessions, cfg.trainConfig)
+	return prg, nil
+}
+
+// writeCheckpoint writes an auto player being trained in epochs to a file along
+// with its settings and progress.
+func writeCheckpoint(ap *autoPlayer, cfg epochConfig, prg progress, path string) error {
+	af := newAgentFile(ap)
+	af.Checkpoint = &checkpointFile{
+		Session:      newSessionFile(cfg.trainConfig),
+		Opponent:     cfg.opp,
+		EvalOpponent: cfg.evalOpp,
+		EpochGames:   cfg.epochGames,
+		EvalGames:    cfg.evalGames,
+		Target:       cfg.target,
+		Patience:     cfg.patience,
+		MinDelta:     cfg.minDelta,
+		GamesPlayed:  prg.gamesPlayed,
+		Epoch:        prg.epoch,
+		BestScore:    prg.bestScore,
+		Stale:        prg.stale,
+		Stopped:      prg.stopped,
+	}
+
+	if err := af.write(path); err != nil {
+		return fmt.Errorf("writeCheckpoint: %v", err)
+	}
+
+	return nil
+}
+
+// readCheckpoint reads an auto player being trained in epochs from a file along
+// with its settings and progress. The settings do not include the checkpoint
+// path or logger.
+func readCheckpoint(path string) (*autoPlayer, epochConfig, progress, error) {
+	af, err := readAgentFile(path)
+	if err != nil {
+		return nil, epochConfig{}, progress{}, fmt.Errorf("readCheckpoint: %v", err)
+	}
+
+	ckpt := af.Checkpoint
+	if ckpt == nil {
+		return nil, epochConfig{}, progress{}, fmt.Errorf("readCheckpoint: %s is not a checkpoint", path)
+	}
+
+	if ckpt.Opponent == "" || ckpt.EvalOpponent == "" || ckpt.EpochGames < 1 {
+		return nil, epochConfig{}, progress{}, fmt.Errorf("readCheckpoint: %s does not record its opponents and epochs", path)
+	}
+
+	ap, err := af.autoPlayer()
+	if err != nil {
+		return nil, epochConfig{}, progress{}, fmt.Errorf("readCheckpoint: %v", err)
+	}
+
+	cfg := epochConfig{
+		opp:        ckpt.Opponent,
+		evalOpp:    ckpt.EvalOpponent,
+		epochGames: ckpt.EpochGames,
+		evalGames:  ckpt.EvalGames,
+		target:     ckpt.Target,
+		patience:   ckpt.Patience,
+		minDelta:   ckpt.MinDelta,
+	}
+
+	if cfg.trainConfig, err = ckpt.Session.trainConfig(); err != nil {
+		return nil, epochConfig{}, progress{}, fmt.Errorf("readCheckpoint: %v", err)
+	}
+
+	prg := progress{
+		gamesPlayed: ckpt.GamesPlayed,
+		epoch:       ckpt.Epoch,
+		bestScore:   ckpt.BestScore,
+		stale:       ckpt.Stale,
+		stopped:     ckpt.Stopped,
+	}
+
+	return ap, cfg, prg, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+// bestResponse finds the pawn options of an auto player's opponent that are best
+// against the auto player's policy. The auto player selects pawn options by its
+// policy and the opponent selects the pawn options of greatest value to it.
+// Positions the auto player has not experienced are played uniformly at random,
+// as they would be on first experiencing them.
+type bestResponse struct {
+	ap    *autoPlayer                   // Auto player responded to
+	value func(op outcomeProbs) float64 // Value to the opponent of outcomes from the auto player's perspective
+	memo  map[uint64]outcomeProbs       // Outcomes of positions searched so far, by zobrist hash
+	moves map[uint64]*pawnOpt           // Pawn option the opponent selects at each position searched, by zobrist hash
+}
+
+// newBestResponse returns a best response to an auto player maximizing a value
+// of the outcomes from the auto player's perspective.
+func newBestResponse(ap *autoPlayer, value func(op outcomeProbs) float64) *bestResponse {
+	return &bestResponse{ap: ap, value: value, memo: make(map[uint64]outcomeProbs), moves: make(map[uint64]*pawnOpt)}
+}
+
+// opponentScore is the expected fraction of points the auto player's opponent
+// earns.
+func opponentScore(op outcomeProbs) float64 {
+	return op.loss + op.draw/2
+}
+
+// opponentWins is the probability the auto player's opponent wins.
+func opponentWins(op outcomeProbs) float64 {
+	return op.loss
+}
+
+// outcome returns the probability of each outcome from the auto player's
+// perspective of a game from a grid and state.
+func (br *bestResponse) outcome(g grid, st state) outcomeProbs {
+	switch {
+	case st == whiteWin && br.ap.sd == whiteSide || st == blackWin && br.ap.sd == blackSide:
+		return outcomeProbs{win: 1}
+	case st == whiteWin || st == blackWin:
+		return outcomeProbs{loss: 1}
+	}
+
+	key := g.zobrist(st)
+	if op, ok := br.memo[key]; ok {
+		return op
+	}
+
+	var op outcomeProbs
+	pos := g.pawnOpts(st)
+	switch {
+	case len(pos) == 0:
+		op.draw = 1
+	case st == turnOf(br.ap.sd):
+		pos, probs, none := br.policy(g, st, pos)
+		op.draw = none // Selecting no pawn option is stalemate
+		for i, po := range pos {
+			if 0 < probs[i] {
+				op.add(br.outcome(g.apply(po, st)), probs[i])
+			}
+		}
+	default:
+		for i, po := range pos {
+			if childOp := br.outcome(g.apply(po, st)); i == 0 || br.value(op) < br.value(childOp) {
+				op, br.moves[key] = childOp, po
+			}
+		}
+	}
+
+	br.memo[key] = op
+	return op
+}
+
+// policy returns the pawn options the auto player selects from at a grid and
+// state, the probability it selects each, and the probability it selects none.
+// The pawn options available are given in case the auto player has not
+// experienced the position.
+func (br *bestResponse) policy(g grid, st state, pos pawnOpts) (pawnOpts, []float64, float64) {
+	if index := br.ap.index(&position{brd: g.toBoard(), st: st}); 0 <= index {
+		pos = br.ap.psns[index].pos
+	}
+
+	probs, none := policy(pos, br.ap.temp)
+	return pos, probs, none
+}
+
+// lineEvent is a ply of a line of play.
+type lineEvent struct {
+	po   *pawnOpt // Pawn option selected; nil if none was
+	st   state    // State the pawn option was selected in
+	prob float64  // Probability the auto player selects the pawn option; one for the opponent
+}
+
+// line returns the line of play from a grid and state in which the opponent
+// selects its best response and the auto player selects its most probable pawn
+// option. The line ends when the game does.
+func (br *bestResponse) line(g grid, st state) []lineEvent {
+	var les []lineEvent
+	for st == whiteTurn || st == blackTurn {
+		br.outcome(g, st)
+		pos := g.pawnOpts(st)
+		if len(pos) == 0 {
+			les = append(les, lineEvent{st: st, prob: 1})
+			break
+		}
+
+		le := lineEvent{st: st, prob: 1}
+		if st == turnOf(br.ap.sd) {
+			pos, probs, none := br.policy(g, st, pos)
+			le.prob = none
+			for i, po := range pos {
+				if le.prob < probs[i] {
+					le.po, le.prob = po, probs[i]
+				}
+			}
+		} else {
+			le.po = br.moves[g.zobrist(st)]
+		}
+
+		les = append(les, le)
+		if le.po == nil {
+			break // Stalemate
+		}
+
+		g, st = g.apply(le.po, st)
+	}
+
+	return les
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// exploitCmd computes the best response to a saved auto player's policy and
+// reports how much more the best response earns than perfect play would, along
+// with the line of play that exploits the auto player.
+func exploitCmd(args []string) error {
+	var (
+		fs    = flag.NewFlagSet("exploit", flag.ContinueOnError)
+		path  = fs.String("agent", "", "agent file to exploit")
+		temp  = fs.Float64("temp", -1, "exploration temperature of the policy (negative uses the agent's own)")
+		objct = fs.String("objective", "score", "what the best response maximizes (score or wins)")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *path == "" {
+		return fmt.Errorf("exploit: no agent file given")
+	}
+
+	var value func(op outcomeProbs) float64
+	switch *objct {
+	case "score":
+		value = opponentScore
+	case "wins":
+		value = opponentWins
+	default:
+		return fmt.Errorf("exploit: unknown objective %q", *objct)
+	}
+
+	ap, err := loadAutoPlayer(*path)
+	if err != nil {
+		return fmt.Errorf("exploit: %v", err)
+	}
+
+	if 0 <= *temp {
+		ap.temp = *temp
+	}
+
+	// Outcome with perfect play from both sides
+	g := newGrid(newBoard(ap.m, ap.n))
+	sol := newSolver().solve(g, whiteTurn)
+	var perfect outcomeProbs // Outcome from the agent's perspective
+	switch sol.res {
+	case draw:
+		perfect = outcomeProbs{draw: 1}
+	case win:
+		perfect = outcomeProbs{win: 1}
+	default:
+		perfect = outcomeProbs{loss: 1}
+	}
+
+	if ap.sd == blackSide {
+		perfect.win, perfect.loss = perfect.loss, perfect.win
+	}
+
+	br := newBestResponse(ap, value)
+	op := br.outcome(g, whiteTurn)
+	fmt.Printf("agent:           %s (%s, %dx%d, temperature %g)\n", *path, sideName(ap.sd), ap.m, ap.n, ap.temp)
+	fmt.Printf("best response:   wins %.3f, losses %.3f, stalemates %.3f, score %.3f\n", op.loss, op.win, op.draw, opponentScore(op))
+	fmt.Printf("perfect play:    %s %.3f\n", *objct, value(perfect))
+	fmt.Printf("exploitability:  %.3f\n\n", value(op)-value(perfect))
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ply\tside\tmove\tplayer\tprobability\t")
+	for k, le := range br.line(g, whiteTurn) {
+		mv := "stalemate"
+		if le.po != nil {
+			mv = moveNotation(le.po, le.st, ap.m)
+		}
+
+		who := "best response"
+		if le.st == turnOf(ap.sd) {
+			who = "agent"
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%.3f\t\n", k+1, sideName(sideOf(le.st)), mv, who, le.prob)
+	}
+
+	return tw.Flush()
+}
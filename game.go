@@ -33,6 +33,20 @@ type game struct {
 	st  state   // Current state
 	md  mode    // Type of game to play
 	hst history // Ordered set of events
+	hsh uint64  // Zobrist hash of the current board and state
+
+	whites pawnList // Squares of white pawns
+	blacks pawnList // Squares of black pawns
+	undos  []undo   // Information needed to take back each event in the history
+}
+
+// undo is the information needed to take back an event.
+type undo struct {
+	st   state  // State before the event
+	hsh  uint64 // Zobrist hash before the event
+	from int    // Square a pawn moved from; -1 if no pawn moved
+	to   int    // Square a pawn moved to
+	capt pawn   // Pawn captured; space if none
 }
 
 // Game constants
@@ -73,38 +87,75 @@ func (gm *game) String() string {
 	bldr.Grow((2*len(gm.brd)+1)*(2*n+1) + 32)
 
 	bldr.Write(gm.brd.toBytes())
-	switch gm.st {
+	bldr.WriteString("\n" + stateName(gm.st) + "\n")
+	return bldr.String()
+}
+
+// stateName returns a description of a state, such as "white to move".
+func stateName(st state) string {
+	switch st {
 	case whiteTurn:
-		bldr.Write([]byte("\nwhite to move\n"))
+		return "white to move"
 	case blackTurn:
-		bldr.Write([]byte("\nblack to move\n"))
+		return "black to move"
 	case whiteWin:
-		bldr.Write([]byte("\nwhite wins\n"))
+		return "white wins"
 	case blackWin:
-		bldr.Write([]byte("\nblack wins\n"))
+		return "black wins"
 	case stalemate:
-		bldr.Write([]byte("\nstalemate\n"))
+		return "stalemate"
 	case illegal:
-		bldr.Write([]byte("\nillegal position\n"))
+		return "illegal position"
 	default:
-		bldr.Write([]byte("\nunknown state\n"))
+		return "unknown state"
 	}
-
-	return bldr.String()
 }
 
 // newGame returns a game to be played.
 func newGame(m, n int, md mode) *game {
-	return &game{
-		brd: newBoard(m, n),
-		st:  whiteTurn,
-		md:  md,
-		hst: make(history, 0, 32), // TODO: Find an upper bound on the total number of legal states depending on input m and n
+	return newGameAt(newBoard(m, n), whiteTurn, md)
+}
+
+// newGameAt returns a game to be played from a board and state. The board is
+// not copied.
+func newGameAt(brd board, st state, md mode) *game {
+	gm := &game{
+		brd:   brd,
+		st:    st,
+		md:    md,
+		hst:   make(history, 0, maxPlies(len(brd), len(brd[0]))),
+		hsh:   zobrist(brd, st),
+		undos: make([]undo, 0, maxPlies(len(brd), len(brd[0]))),
+	}
+
+	gm.whites, gm.blacks = newPawnLists(brd)
+	return gm
+}
+
+// position returns the current position of a game. The board is not copied, so
+// the position is only valid until the game's next move.
+func (gm *game) position() *position {
+	return &position{brd: gm.brd, st: gm.st, pos: gm.pawnOpts(), hsh: gm.key()}
+}
+
+// playGame plays a game between two players on an m-by-n board until it is won or
+// a stalemate is reached.
+func playGame(white, black player, m, n int) *game {
+	gm := newGame(m, n, cvc)
+	for {
+		switch gm.st {
+		case whiteTurn:
+			gm.move(white.chooseEvent(gm.position()))
+		case blackTurn:
+			gm.move(black.chooseEvent(gm.position()))
+		default:
+			return gm
+		}
 	}
 }
 
 // play
-func play(m, n int, md mode) {
+func play(m, n int, md mode, seed int64) {
 	gm := newGame(m, n, md)
 	trainSessions := 100000
 	learningRate := weight(0.1)
@@ -112,14 +163,14 @@ func play(m, n int, md mode) {
 
 	switch md {
 	case cvc:
-		white := newAutoPlayer(whiteSide, m, n)
-		black := newAutoPlayer(blackSide, m, n)
+		white := newAutoPlayer(whiteSide, m, n, deriveSeed(seed, 0))
+		black := newAutoPlayer(blackSide, m, n, deriveSeed(seed, 1))
 		white.train(trainSessions, learningRate)
 		black.train(trainSessions, learningRate)
 		var gameOver bool
 
 		for !gameOver {
-			psn = &position{brd: gm.brd, st: gm.st, pos: availPawnOpts(gm.brd, gm.st)}
+			psn = &position{brd: gm.brd, st: gm.st, pos: availPawnOpts(gm.brd, gm.st), hsh: gm.key()}
 
 			switch gm.st {
 			case whiteTurn:
@@ -153,68 +204,24 @@ func play(m, n int, md mode) {
 	}
 }
 
-func playNGames(numGames, numTrainSessions int, learningRate weight, m, n int, md mode) string {
-	var (
-		gm         *game     // Game to be played
-		psn        *position // Position at each turn
-		whiteWins  int       // Number of white wins
-		blackWins  int       // Number of black wins
-		stalemates int       // Number of stalemates reached
-	)
-
-	switch md {
-	case cvc:
-		white := newAutoPlayer(whiteSide, m, n)
-		black := newAutoPlayer(blackSide, m, n)
-		white.train(numTrainSessions, learningRate)
-		black.train(numTrainSessions, learningRate)
-
-		for ; 0 < numGames; numGames-- {
-			gm = newGame(m, n, md)
-			fmt.Println(gm)
-			for {
-				psn = &position{
-					brd: gm.brd,
-					st:  gm.st,
-					pos: availPawnOpts(gm.brd, gm.st),
-				}
-
-				if gm.st == whiteTurn {
-					gm.move(white.chooseEvent(psn))
-					fmt.Println(gm)
-					continue
-				}
-
-				if gm.st == blackTurn {
-					gm.move(black.chooseEvent(psn))
-					fmt.Println(gm)
-					continue
-				}
-
-				break
-			}
+// playNGames trains a white and a black auto player on a number of random games
+// each and returns the results of a number of games played between them.
+func playNGames(numGames, numTrainSessions int, learningRate weight, m, n int, md mode, seed int64) (*matchResults, error) {
+	if md != cvc {
+		return nil, fmt.Errorf("playNGames: mode %d is not supported", md)
+	}
 
-			switch gm.st {
-			case whiteWin:
-				whiteWins++
-			case blackWin:
-				blackWins++
-			case stalemate:
-				stalemates++
-			default:
-				log.Fatal("playNGames: invalid endgame state")
-			}
-		}
+	white := newAutoPlayer(whiteSide, m, n, deriveSeed(seed, 0))
+	black := newAutoPlayer(blackSide, m, n, deriveSeed(seed, 1))
+	white.train(numTrainSessions, learningRate)
+	black.train(numTrainSessions, learningRate)
 
-		fmt.Printf("%s\nwhite boards: %d\nblack boards: %d\n\n", gm, len(white.psns), len(black.psns))
-	case cvp: // TODO
-	case pvc: // TODO
-	case pvp: // TODO
-	default:
-		log.Fatal("playNGames: invalid mode")
+	mr, err := playMatch(white, black, "white", "black", m, n, numGames, deriveSeed(seed, 2))
+	if err != nil {
+		return nil, fmt.Errorf("playNGames: %v", err)
 	}
 
-	return fmt.Sprintf("white wins:  %d\nblack wins:  %d\nstalemates:  %d\n---------------\n     total: %d", whiteWins, blackWins, stalemates, whiteWins+blackWins+stalemates)
+	return mr, nil
 }
 
 // turn
@@ -243,6 +250,8 @@ func (gm *game) turn() {
 
 // move performs an action altering the position of the board.
 func (gm *game) move(evnt *event) {
+	st := gm.st
+	gm.undos = append(gm.undos, undo{st: gm.st, hsh: gm.hsh, from: -1})
 	if evnt.poSlc != nil {
 		m, n := evnt.poSlc.m, evnt.poSlc.n
 		act := evnt.poSlc.act
@@ -252,18 +261,15 @@ func (gm *game) move(evnt *event) {
 			switch act {
 			case forward:
 				if 0 < m && gm.brd[m-1][n] == space {
-					gm.brd[m-1][n] = whitePawn
-					gm.brd[m][n] = space
+					gm.shift(m, n, m-1, n)
 				}
 			case captureLeft:
 				if 0 < m && 0 < n && gm.brd[m-1][n-1] == blackPawn {
-					gm.brd[m-1][n-1] = whitePawn
-					gm.brd[m][n] = space
+					gm.shift(m, n, m-1, n-1)
 				}
 			case captureRight:
 				if 0 < m && n+1 < len(gm.brd[0]) && gm.brd[m-1][n+1] == blackPawn {
-					gm.brd[m-1][n+1] = whitePawn
-					gm.brd[m][n] = space
+					gm.shift(m, n, m-1, n+1)
 				}
 			}
 
@@ -276,18 +282,15 @@ func (gm *game) move(evnt *event) {
 			switch act {
 			case forward:
 				if m+1 < len(gm.brd) && gm.brd[m+1][n] == space {
-					gm.brd[m+1][n] = blackPawn
-					gm.brd[m][n] = space
+					gm.shift(m, n, m+1, n)
 				}
 			case captureLeft:
 				if m+1 < len(gm.brd) && n+1 < len(gm.brd[0]) && gm.brd[m+1][n+1] == whitePawn {
-					gm.brd[m+1][n+1] = blackPawn
-					gm.brd[m][n] = space
+					gm.shift(m, n, m+1, n+1)
 				}
 			case captureRight:
-				if m+1 < len(gm.brd) && n-1 < len(gm.brd[0]) && gm.brd[m+1][n-1] == whitePawn {
-					gm.brd[m+1][n-1] = blackPawn
-					gm.brd[m][n] = space
+				if m+1 < len(gm.brd) && 0 < n && gm.brd[m+1][n-1] == whitePawn {
+					gm.shift(m, n, m+1, n-1)
 				}
 			}
 
@@ -305,9 +308,108 @@ func (gm *game) move(evnt *event) {
 		gm.st = stalemate // No pawn option selected is stalemate
 	}
 
+	gm.hsh ^= zobristState(st) ^ zobristState(gm.st)
 	gm.hst = append(gm.hst, evnt)
 }
 
+// shift a pawn from one square to another, capturing any pawn on the square it
+// moves to, and update the game's zobrist hash and pawn lists. The move is
+// recorded so it can be taken back.
+func (gm *game) shift(i0, j0, i1, j1 int) {
+	var (
+		n        = len(gm.brd[0])
+		from, to = i0*n + j0, i1*n + j1
+		p, q     = gm.brd[i0][j0], gm.brd[i1][j1]
+		u        = &gm.undos[len(gm.undos)-1]
+	)
+
+	u.from, u.to, u.capt = from, to, q
+	gm.hsh ^= zobristPawn(i0, j0, p) ^ zobristPawn(i1, j1, p)
+	if q != space {
+		gm.hsh ^= zobristPawn(i1, j1, q)
+	}
+
+	if p == whitePawn {
+		gm.whites = gm.whites.remove(from).insert(to)
+		if q == blackPawn {
+			gm.blacks = gm.blacks.remove(to)
+		}
+	} else {
+		gm.blacks = gm.blacks.remove(from).insert(to)
+		if q == whitePawn {
+			gm.whites = gm.whites.remove(to)
+		}
+	}
+
+	gm.brd[i1][j1] = p
+	gm.brd[i0][j0] = space
+}
+
+// undo takes back the last event of a game, restoring its board, state, zobrist
+// hash, and pawn lists, and returns the event. Panics if there is no event to
+// take back.
+func (gm *game) undo() *event {
+	if len(gm.hst) == 0 {
+		panic("undo: no event to take back")
+	}
+
+	var (
+		evnt = gm.hst[len(gm.hst)-1]
+		u    = gm.undos[len(gm.undos)-1]
+		n    = len(gm.brd[0])
+	)
+
+	gm.hst = gm.hst[:len(gm.hst)-1]
+	gm.undos = gm.undos[:len(gm.undos)-1]
+	gm.st, gm.hsh = u.st, u.hsh
+	if u.from < 0 {
+		return evnt // No pawn moved
+	}
+
+	p := gm.brd[u.to/n][u.to%n]
+	gm.brd[u.from/n][u.from%n] = p
+	gm.brd[u.to/n][u.to%n] = u.capt
+	if p == whitePawn {
+		gm.whites = gm.whites.remove(u.to).insert(u.from)
+		if u.capt == blackPawn {
+			gm.blacks = gm.blacks.insert(u.to)
+		}
+	} else {
+		gm.blacks = gm.blacks.remove(u.to).insert(u.from)
+		if u.capt == whitePawn {
+			gm.whites = gm.whites.insert(u.to)
+		}
+	}
+
+	return evnt
+}
+
+// key returns the zobrist hash of a game's board and state, a stable 64-bit key
+// identifying its position.
+func (gm *game) key() uint64 {
+	return gm.hsh
+}
+
+// applyPawnOpt returns the board and state reached by selecting an available
+// pawn option at a board and state. The given board is not altered.
+func applyPawnOpt(brd board, st state, po *pawnOpt) (board, state) {
+	cpy := copyBoard(brd)
+	i, j := po.target(st)
+	cpy[i][j], cpy[po.m][po.n] = cpy[po.m][po.n], space
+
+	win := checkWin(cpy, st)
+	switch {
+	case st == whiteTurn && win:
+		return cpy, whiteWin
+	case st == whiteTurn:
+		return cpy, blackTurn
+	case win:
+		return cpy, blackWin
+	default:
+		return cpy, whiteTurn
+	}
+}
+
 // availActions returns a set of actions that can be taken at a position (m,n).
 // Actions are available if the state is either white or black turn.
 func availActions(m, n int, brd board, st state) []action {
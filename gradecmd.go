@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// gradeCmd grades a saved auto player against perfect play and reports the
+// percentage of positions its pawn option of highest weight is optimal at, the
+// outcome of its policy against a perfect opponent, and the positions it plays
+// worst.
+func gradeCmd(args []string) error {
+	var (
+		fs    = flag.NewFlagSet("grade", flag.ContinueOnError)
+		path  = fs.String("agent", "", "agent file to grade")
+		temp  = fs.Float64("temp", -1, "exploration temperature of the policy (negative uses the agent's own)")
+		worst = fs.Int("worst", 10, "number of worst positions listed")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *path == "" {
+		return fmt.Errorf("grade: no agent file given")
+	}
+
+	ap, err := loadAutoPlayer(*path)
+	if err != nil {
+		return fmt.Errorf("grade: %v", err)
+	}
+
+	if 0 <= *temp {
+		ap.temp = *temp
+	}
+
+	rep := gradeAutoPlayer(ap, newSolver())
+	fmt.Printf("agent:        %s (%s, %dx%d)\n", *path, sideName(ap.sd), ap.m, ap.n)
+	fmt.Printf("positions:    %d\n", len(rep.grades))
+	fmt.Printf("optimal:      %d (%.1f%%)\n", rep.optimal, rep.percentOptimal())
+	fmt.Printf("vs perfect:   wins %.3f, losses %.3f, stalemates %.3f, score %.3f (temperature %g)\n", rep.expected.win, rep.expected.loss, rep.expected.draw, rep.expected.score(), ap.temp)
+
+	if *worst <= 0 || len(rep.grades) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "position\tgreedy\tresult\tbest\tresult\toptimal prob\t")
+	for k, pg := range rep.grades {
+		if k == *worst || pg.optimal() && pg.optProb == 1 {
+			break
+		}
+
+		m := len(pg.psn.brd)
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%s\t%v\t%.3f\t\n", formatPosition(pg.psn.brd, pg.psn.st), moveNotation(pg.greedy, pg.psn.st, m), pg.greedySol, moveNotation(pg.bestPo, pg.psn.st, m), pg.best, pg.optProb)
+	}
+
+	return tw.Flush()
+}
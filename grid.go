@@ -0,0 +1,43 @@
+package main
+
+// grid is a representation of the pawns on a board that game logic can be
+// played on. Boards and bitboards are both grids, so searches written for grids
+// play the same game on either.
+type grid interface {
+	dims() (int, int)                          // Number of rows and columns
+	pawnOpts(st state) pawnOpts                // Pawn options available in a state
+	apply(po *pawnOpt, st state) (grid, state) // Grid and state reached by selecting a pawn option
+	zobrist(st state) uint64                   // Zobrist hash of the grid and a state
+	toBoard() board                            // Board the grid represents
+}
+
+// newGrid returns the fastest grid representing a board: a bitboard if the board
+// has at most 64 squares and a copy of the board otherwise.
+func newGrid(brd board) grid {
+	if fitsBitboard(len(brd), len(brd[0])) {
+		return toBitboard(brd)
+	}
+
+	return copyBoard(brd)
+}
+
+// dims returns the number of rows and columns of a board.
+func (brd board) dims() (int, int) {
+	return len(brd), len(brd[0])
+}
+
+// pawnOpts returns the set of pawn options available at a board and state.
+func (brd board) pawnOpts(st state) pawnOpts {
+	return availPawnOpts(brd, st)
+}
+
+// apply returns the board and state reached by selecting a pawn option. The
+// board is not altered.
+func (brd board) apply(po *pawnOpt, st state) (grid, state) {
+	return applyPawnOpt(brd, st, po)
+}
+
+// toBoard returns a board.
+func (brd board) toBoard() board {
+	return brd
+}
@@ -0,0 +1,78 @@
+package main
+
+import "testing"
+
+// gridPositions are positions whose game trees are walked to compare grid
+// representations.
+var gridPositions = []struct {
+	psn   string // Position in notation
+	depth int    // Number of plies walked
+}{
+	{psn: "bbb/3/www w", depth: 10},
+	{psn: "bbbb/4/wwww w", depth: 10},
+	{psn: "bbbb/4/4/wwww w", depth: 8},
+	{psn: "bbbbb/5/5/5/wwwww w", depth: 5},
+	{psn: "bbbbbbbb/8/8/8/8/8/8/wwwwwwww w", depth: 3},
+	{psn: "b1b1/1w2/2w1/w3 b", depth: 8},
+}
+
+// TestGrids checks that boards and bitboards generate the same pawn options and
+// reach the same boards and states throughout the game trees of each position.
+func TestGrids(t *testing.T) {
+	for _, gp := range gridPositions {
+		brd, st, err := parsePosition(gp.psn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !fitsBitboard(len(brd), len(brd[0])) {
+			t.Fatalf("%q does not fit a bitboard", gp.psn)
+		}
+
+		if bb := toBitboard(brd); !equalBoards(bb.toBoard(), brd) {
+			t.Fatalf("%q: bitboard converts back to\n%v", gp.psn, bb.toBoard())
+		}
+
+		compareGrids(t, copyBoard(brd), toBitboard(brd), st, gp.depth)
+	}
+}
+
+// compareGrids walks the game trees of a board and a bitboard representing the
+// same position in lockstep, failing the test where they differ.
+func compareGrids(t *testing.T, brd, bb grid, st state, depth int) {
+	t.Helper()
+	if depth == 0 || st != whiteTurn && st != blackTurn {
+		return
+	}
+
+	brdPos, bbPos := brd.pawnOpts(st), bb.pawnOpts(st)
+	if len(brdPos) != len(bbPos) {
+		t.Fatalf("%s: board has %d pawn options, bitboard has %d", formatPosition(brd.toBoard(), st), len(brdPos), len(bbPos))
+	}
+
+	for _, po := range brdPos {
+		if !containsPawnOpt(bbPos, po) {
+			t.Fatalf("%s: bitboard lacks pawn option %v", formatPosition(brd.toBoard(), st), po)
+		}
+
+		brdChild, brdSt := brd.apply(po, st)
+		bbChild, bbSt := bb.apply(po, st)
+		if brdSt != bbSt || !equalBoards(brdChild.toBoard(), bbChild.toBoard()) {
+			t.Fatalf("%s: %s reaches %s on a board and %s on a bitboard", formatPosition(brd.toBoard(), st), moveNotation(po, st, len(brd.toBoard())), stateName(brdSt), stateName(bbSt))
+		}
+
+		compareGrids(t, brdChild, bbChild, brdSt, depth-1)
+	}
+}
+
+// containsPawnOpt returns true if a set of pawn options contains one moving the
+// same pawn by the same action as a pawn option.
+func containsPawnOpt(pos pawnOpts, po *pawnOpt) bool {
+	for _, p := range pos {
+		if p.m == po.m && p.n == po.n && p.act == po.act {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Boards with at most this many squares are analyzed by the solver and larger
+// boards by search to a depth.
+const (
+	analysisSolveSquares = 20
+	analysisDepth        = 8
+)
+
+// moveHint describes a pawn option available at a position: how likely an auto
+// player is to select it, its result with perfect play, and what it does.
+type moveHint struct {
+	po     *pawnOpt // Pawn option described
+	prob   float64  // Probability the auto player selects the pawn option; negative if there is no auto player
+	sol    solution // Solution of selecting the pawn option for the side selecting it
+	solved bool     // Indicates the solution was found
+	note   string   // Explanation of what the pawn option does
+}
+
+// hints returns a hint for each pawn option available at a board and state,
+// best first. The auto player and solver may each be nil, in which case no
+// probabilities or solutions are given.
+func hints(brd board, st state, ap *autoPlayer, slv *solver) []*moveHint {
+	var (
+		g     = newGrid(brd)
+		pos   = g.pawnOpts(st)
+		probs []float64
+		mhs   = make([]*moveHint, 0, len(pos))
+	)
+
+	if ap != nil {
+		probs = agentProbs(ap, brd, st, pos)
+	}
+
+	for i, po := range pos {
+		mh := &moveHint{po: po, prob: -1, note: explain(g, st, po)}
+		if probs != nil {
+			mh.prob = probs[i]
+		}
+
+		if slv != nil {
+			mh.sol, mh.solved = slv.solvePawnOpt(g, st, po), true
+		}
+
+		mhs = append(mhs, mh)
+	}
+
+	sort.SliceStable(mhs, func(i, j int) bool {
+		if mhs[i].solved && mhs[i].sol != mhs[j].sol {
+			return mhs[i].sol.better(mhs[j].sol)
+		}
+
+		return mhs[j].prob < mhs[i].prob
+	})
+
+	return mhs
+}
+
+// agentProbs returns the probability an auto player selects each of the pawn
+// options available at a board and state. Positions the auto player has not
+// experienced are given the probabilities it would select from on first
+// experiencing them.
+func agentProbs(ap *autoPlayer, brd board, st state, pos pawnOpts) []float64 {
+	index := ap.index(&position{brd: brd, st: st})
+	if index < 0 {
+		probs, _ := policy(pos, ap.temp)
+		return probs
+	}
+
+	var (
+		known         = ap.psns[index].pos
+		knownProbs, _ = policy(known, ap.temp)
+		probs         = make([]float64, len(pos))
+	)
+
+	for i, po := range pos {
+		for j, kpo := range known {
+			if po.m == kpo.m && po.n == kpo.n && po.act == kpo.act {
+				probs[i] = knownProbs[j]
+				break
+			}
+		}
+	}
+
+	return probs
+}
+
+// explain returns a one line explanation of selecting a pawn option at a grid
+// and state, such as "captures; threatens to promote".
+func explain(g grid, st state, po *pawnOpt) string {
+	var (
+		m, _         = g.dims()
+		i, j         = po.target(st)
+		child, nxtSt = g.apply(po, st)
+		notes        = make([]string, 0, 3)
+	)
+
+	if po.act != forward {
+		notes = append(notes, "captures")
+	}
+
+	switch {
+	case nxtSt == whiteWin || nxtSt == blackWin:
+		if i == 0 || i == m-1 {
+			return strings.Join(append(notes, "promotes and wins"), "; ")
+		}
+
+		return strings.Join(append(notes, "leaves the opponent no pawns and wins"), "; ")
+	case len(child.pawnOpts(nxtSt)) == 0:
+		return strings.Join(append(notes, "leaves the opponent no moves, ending in stalemate"), "; ")
+	}
+
+	if promotes, wins := winsNext(child, nxtSt); promotes {
+		notes = append(notes, "allows opponent promotion next move")
+	} else if wins {
+		notes = append(notes, "allows the opponent to win next move")
+	}
+
+	if attacked(child, nxtSt, i, j) {
+		notes = append(notes, "can be captured")
+	}
+
+	if promotes, _ := winsNext(child, st); promotes {
+		notes = append(notes, "threatens to promote")
+	}
+
+	if len(notes) == 0 {
+		notes = append(notes, "advances")
+	}
+
+	return strings.Join(notes, "; ")
+}
+
+// winsNext returns whether the side to move in a state could promote a pawn at a
+// grid on its next move, and whether it could win on its next move at all. The
+// state need not be the state of the grid, so threats of the side that just
+// moved may be found.
+func winsNext(g grid, st state) (bool, bool) {
+	var (
+		m, _  = g.dims()
+		wins  bool
+		final = 0 // Row the side to move promotes on
+	)
+
+	if st == blackTurn {
+		final = m - 1
+	}
+
+	for _, po := range g.pawnOpts(st) {
+		if _, nxtSt := g.apply(po, st); nxtSt == whiteWin || nxtSt == blackWin {
+			if i, _ := po.target(st); i == final {
+				return true, true
+			}
+
+			wins = true
+		}
+	}
+
+	return false, wins
+}
+
+// attacked returns true if the side to move in a state can capture a pawn at row
+// i0 and column j0 of a grid.
+func attacked(g grid, st state, i0, j0 int) bool {
+	for _, po := range g.pawnOpts(st) {
+		if i, j := po.target(st); po.act != forward && i == i0 && j == j0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeHints writes a table of hints at a state on a board with m rows.
+func writeHints(w io.Writer, mhs []*moveHint, st state, m int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "move\tagent\tsolver\tnote\t")
+	for _, mh := range mhs {
+		prob, sol := "-", "-"
+		if 0 <= mh.prob {
+			prob = fmt.Sprintf("%.0f%%", 100*mh.prob)
+		}
+
+		if mh.solved {
+			sol = mh.sol.String()
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", moveNotation(mh.po, st, m), prob, sol, mh.note)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// hintCmd lists each pawn option available at a position with the probability a
+// saved auto player selects it, its result with perfect play, and what it does.
+func hintCmd(args []string) error {
+	var (
+		fs    = flag.NewFlagSet("hint", flag.ContinueOnError)
+		m     = fs.Int("m", 3, "number of rows")
+		n     = fs.Int("n", 3, "number of columns")
+		psn   = fs.String("position", "", "position in notation, such as \"bbb/3/www w\" (default the starting position)")
+		agent = fs.String("agent", "", "agent file whose probabilities are listed")
+		solve = fs.Bool("solve", true, fmt.Sprintf("list the result of each move with perfect play on boards of at most %d squares", analysisSolveSquares))
+		depth = fs.Int("depth", analysisDepth, "depth searched for the best move on larger boards")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var (
+		brd board
+		st  = whiteTurn
+	)
+
+	if *psn == "" {
+		if *m < 3 || *n < 3 {
+			return fmt.Errorf("hint: invalid dimensions %dx%d", *m, *n)
+		}
+
+		brd = newBoard(*m, *n)
+	} else {
+		var err error
+		if brd, st, err = parsePosition(*psn); err != nil {
+			return fmt.Errorf("hint: %v", err)
+		}
+	}
+
+	var ap *autoPlayer
+	if *agent != "" {
+		var err error
+		if ap, err = loadAutoPlayer(*agent); err != nil {
+			return fmt.Errorf("hint: %v", err)
+		}
+
+		if ap.m != len(brd) || ap.n != len(brd[0]) {
+			return fmt.Errorf("hint: %s plays on %dx%d boards, not %dx%d", *agent, ap.m, ap.n, len(brd), len(brd[0]))
+		}
+
+		if turnOf(ap.sd) != st {
+			return fmt.Errorf("hint: %s plays %s, but it is %s's turn", *agent, sideName(ap.sd), sideName(sideOf(st)))
+		}
+	}
+
+	if *depth < 1 {
+		return fmt.Errorf("hint: invalid depth %d", *depth)
+	}
+
+	var slv *solver
+	if *solve && len(brd)*len(brd[0]) <= analysisSolveSquares {
+		slv = newSolver()
+	}
+
+	mhs := hints(brd, st, ap, slv)
+	if err := writeHints(os.Stdout, mhs, st, len(brd)); err != nil {
+		return err
+	}
+
+	if *solve && slv == nil && len(mhs) != 0 {
+		evnt := newSearchPlayer(*depth).chooseEvent(newGameAt(brd, st, cvc).position())
+		fmt.Printf("search: %s\n", moveNotation(evnt.poSlc, st, len(brd)))
+	}
+
+	return nil
+}
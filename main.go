@@ -2,17 +2,58 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
-	"time"
+	"os"
 )
 
+// commands maps each subcommand to the function that runs it given its
+// arguments.
+var commands = map[string]func(args []string) error{
+	"dot":        dotCmd,
+	"exploit":    exploitCmd,
+	"grade":      gradeCmd,
+	"hint":       hintCmd,
+	"perft":      perftCmd,
+	"play":       playCmd,
+	"ratings":    ratingsCmd,
+	"render":     renderCmd,
+	"repl":       replCmd,
+	"review":     reviewCmd,
+	"solve":      solveCmd,
+	"space":      spaceCmd,
+	"sprt":       sprtCmd,
+	"tournament": tournamentCmd,
+	"train":      trainCmd,
+}
+
 func main() {
-	rand.Seed(int64(time.Now().Nanosecond()))
-	fmt.Println(playNGames(1, 1000, 0.1, 3, 3, cvc))
+	if len(os.Args) < 2 {
+		mr, err := playNGames(1, 1000, 0.1, 3, 3, cvc, clockSeed())
+		if err == nil {
+			err = writeMatchText(os.Stdout, mr)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if err := run(os.Args[1], os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
 
-func run() {
+// run a subcommand with its arguments.
+func run(cmd string, args []string) error {
+	f, ok := commands[cmd]
+	if !ok {
+		return fmt.Errorf("unknown command %q", cmd)
+	}
 
+	return f(args)
 }
 
 func readMove(s state) (int, int, action) {
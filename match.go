@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// matchResults is the outcome of a number of games between a white and a black
+// player.
+type matchResults struct {
+	m          int       // Number of rows
+	n          int       // Number of columns
+	seed       int64     // Seed each game's seed is derived from
+	white      string    // Name of the white player
+	black      string    // Name of the black player
+	whiteWins  int       // Number of games won by white
+	blackWins  int       // Number of games won by black
+	stalemates int       // Number of games ending in stalemate
+	lengths    []int     // Number of plies of each game
+	records    []*record // Record of each game
+}
+
+// interval is an estimate of a proportion with a confidence interval.
+type interval struct {
+	Rate  float64 `json:"rate"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// confidenceZ is the standard score of the 95% confidence intervals reported.
+const confidenceZ = 1.959964
+
+// playMatch plays a number of games between named white and black players on an
+// m-by-n board. Each game is seeded from the seed and its number.
+func playMatch(white, black player, whiteName, blackName string, m, n, numGames int, seed int64) (*matchResults, error) {
+	mr := &matchResults{
+		m:       m,
+		n:       n,
+		seed:    seed,
+		white:   whiteName,
+		black:   blackName,
+		lengths: make([]int, 0, numGames),
+		records: make([]*record, 0, numGames),
+	}
+
+	for k := 0; k < numGames; k++ {
+		gmSeed := deriveSeed(seed, int64(k))
+		gm := playSeeded(white, black, m, n, gmSeed)
+		switch gm.st {
+		case whiteWin:
+			mr.whiteWins++
+		case blackWin:
+			mr.blackWins++
+		case stalemate:
+			mr.stalemates++
+		default:
+			return nil, fmt.Errorf("playMatch: game %d ended in invalid state %d", k+1, gm.st)
+		}
+
+		mr.lengths = append(mr.lengths, len(gm.hst))
+		mr.records = append(mr.records, newRecord(gm, gmSeed, whiteName, blackName))
+	}
+
+	return mr, nil
+}
+
+// games returns the number of games played.
+func (mr *matchResults) games() int {
+	return mr.whiteWins + mr.blackWins + mr.stalemates
+}
+
+// rate returns the proportion of games a count of games is, with its 95% Wilson
+// score interval.
+func (mr *matchResults) rate(count int) interval {
+	return wilson(count, mr.games(), confidenceZ)
+}
+
+// wilson returns the proportion of k successes in n trials with its Wilson score
+// interval at a standard score z. The interval is empty at zero if there were no
+// trials.
+func wilson(k, n int, z float64) interval {
+	if n == 0 {
+		return interval{}
+	}
+
+	var (
+		p      = float64(k) / float64(n)
+		nf     = float64(n)
+		denom  = 1 + z*z/nf
+		center = (p + z*z/(2*nf)) / denom
+		margin = z * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf)) / denom
+	)
+
+	return interval{Rate: p, Lower: math.Max(center-margin, 0), Upper: math.Min(center+margin, 1)}
+}
+
+// lengthStats returns the mean, least, and greatest number of plies of the games
+// played.
+func (mr *matchResults) lengthStats() (float64, int, int) {
+	if len(mr.lengths) == 0 {
+		return 0, 0, 0
+	}
+
+	var (
+		sum         int
+		least, most = mr.lengths[0], mr.lengths[0]
+	)
+
+	for _, l := range mr.lengths {
+		sum += l
+		if l < least {
+			least = l
+		}
+
+		if most < l {
+			most = l
+		}
+	}
+
+	return float64(sum) / float64(len(mr.lengths)), least, most
+}
+
+// matchReporter returns the function writing match results in a format: text,
+// json, or csv.
+func matchReporter(format string) (func(w io.Writer, mr *matchResults) error, error) {
+	switch format {
+	case "text":
+		return writeMatchText, nil
+	case "json":
+		return writeMatchJSON, nil
+	case "csv":
+		return writeMatchCSV, nil
+	default:
+		return nil, fmt.Errorf("matchReporter: unknown format %q", format)
+	}
+}
+
+// writeMatchText writes a summary of match results.
+func writeMatchText(w io.Writer, mr *matchResults) error {
+	bldr := strings.Builder{}
+	fmt.Fprintf(&bldr, "%s (white) vs %s (black), %dx%d, seed %d\n", mr.white, mr.black, mr.m, mr.n, mr.seed)
+	for _, row := range []struct {
+		name  string
+		count int
+	}{
+		{name: "white wins", count: mr.whiteWins},
+		{name: "black wins", count: mr.blackWins},
+		{name: "stalemates", count: mr.stalemates},
+	} {
+		iv := mr.rate(row.count)
+		fmt.Fprintf(&bldr, "%s:  %d (%.1f%%, 95%% CI %.1f%%-%.1f%%)\n", row.name, row.count, 100*iv.Rate, 100*iv.Lower, 100*iv.Upper)
+	}
+
+	mean, least, most := mr.lengthStats()
+	fmt.Fprintf(&bldr, "---------------\n     total: %d\n    length: %.1f plies (%d-%d)\n", mr.games(), mean, least, most)
+	_, err := io.WriteString(w, bldr.String())
+	return err
+}
+
+// matchFile is the JSON form of match results.
+type matchFile struct {
+	Rows          int       `json:"rows"`
+	Columns       int       `json:"columns"`
+	Seed          int64     `json:"seed"`
+	White         string    `json:"white"`
+	Black         string    `json:"black"`
+	Games         int       `json:"games"`
+	WhiteWins     int       `json:"whiteWins"`
+	BlackWins     int       `json:"blackWins"`
+	Stalemates    int       `json:"stalemates"`
+	WhiteWinRate  interval  `json:"whiteWinRate"`
+	BlackWinRate  interval  `json:"blackWinRate"`
+	StalemateRate interval  `json:"stalemateRate"`
+	Lengths       []int     `json:"lengths"`
+	Records       []*record `json:"records"`
+}
+
+// writeMatchJSON writes match results, including the record of each game, as
+// JSON.
+func writeMatchJSON(w io.Writer, mr *matchResults) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(matchFile{
+		Rows:          mr.m,
+		Columns:       mr.n,
+		Seed:          mr.seed,
+		White:         mr.white,
+		Black:         mr.black,
+		Games:         mr.games(),
+		WhiteWins:     mr.whiteWins,
+		BlackWins:     mr.blackWins,
+		Stalemates:    mr.stalemates,
+		WhiteWinRate:  mr.rate(mr.whiteWins),
+		BlackWinRate:  mr.rate(mr.blackWins),
+		StalemateRate: mr.rate(mr.stalemates),
+		Lengths:       mr.lengths,
+		Records:       mr.records,
+	})
+}
+
+// writeMatchCSV writes a row of comma separated values for each game of a match,
+// with a header.
+func writeMatchCSV(w io.Writer, mr *matchResults) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"game", "seed", "white", "black", "result", "plies", "moves"})
+	for k, rec := range mr.records {
+		cw.Write([]string{
+			strconv.Itoa(k + 1),
+			strconv.FormatInt(rec.Seed, 10),
+			rec.White,
+			rec.Black,
+			rec.Result,
+			strconv.Itoa(mr.lengths[k]),
+			strings.Join(rec.Moves, " "),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// TestWilson checks Wilson score intervals against known values.
+func TestWilson(t *testing.T) {
+	tests := []struct {
+		k, n int
+		want interval
+	}{
+		{k: 0, n: 0, want: interval{}},
+		{k: 5, n: 10, want: interval{Rate: 0.5, Lower: 0.236593, Upper: 0.763407}},
+		{k: 0, n: 10, want: interval{Rate: 0, Lower: 0, Upper: 0.277533}},
+		{k: 10, n: 10, want: interval{Rate: 1, Lower: 0.722467, Upper: 1}},
+		{k: 81, n: 263, want: interval{Rate: 0.307985, Lower: 0.255289, Upper: 0.366210}},
+	}
+
+	for _, test := range tests {
+		got := wilson(test.k, test.n, confidenceZ)
+		if 1e-6 < math.Abs(got.Rate-test.want.Rate) || 1e-6 < math.Abs(got.Lower-test.want.Lower) || 1e-6 < math.Abs(got.Upper-test.want.Upper) {
+			t.Errorf("wilson(%d, %d): expected %+v, got %+v", test.k, test.n, test.want, got)
+		}
+	}
+}
+
+// TestPlayMatch checks the results of matches with known outcomes: perfect play
+// ends in stalemate on 3x3 boards and in a white win on 5x3 boards.
+func TestPlayMatch(t *testing.T) {
+	tests := []struct {
+		m, n                             int
+		whiteWins, blackWins, stalemates int
+		plies                            int
+	}{
+		{m: 3, n: 3, stalemates: 4, plies: 4},
+		{m: 5, n: 3, whiteWins: 4},
+	}
+
+	for _, test := range tests {
+		mr, err := playMatch(newSolverPlayer(), newSolverPlayer(), "white", "black", test.m, test.n, 4, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mr.whiteWins != test.whiteWins || mr.blackWins != test.blackWins || mr.stalemates != test.stalemates {
+			t.Errorf("%dx%d: expected %d-%d-%d, got %d-%d-%d", test.m, test.n, test.whiteWins, test.blackWins, test.stalemates, mr.whiteWins, mr.blackWins, mr.stalemates)
+		}
+
+		if mean, least, most := mr.lengthStats(); test.plies != 0 && (mean != float64(test.plies) || least != test.plies || most != test.plies) {
+			t.Errorf("%dx%d: expected games of %d plies, got %.1f (%d-%d)", test.m, test.n, test.plies, mean, least, most)
+		}
+	}
+}
+
+// TestMatchRecords checks that the records of a match replay to its results and
+// that its JSON report agrees with it.
+func TestMatchRecords(t *testing.T) {
+	mr, err := playMatch(newRandomPlayer(0), newRandomPlayer(0), "random", "random", 3, 3, 50, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mr.games() != 50 || len(mr.records) != 50 || len(mr.lengths) != 50 {
+		t.Fatalf("expected 50 games, got %d with %d records and %d lengths", mr.games(), len(mr.records), len(mr.lengths))
+	}
+
+	results := make(map[state]int)
+	for k, rec := range mr.records {
+		gm, err := rec.game()
+		if err != nil {
+			t.Fatalf("record %d: %v", k+1, err)
+		}
+
+		if len(gm.hst) != mr.lengths[k] {
+			t.Errorf("record %d: expected %d plies, got %d", k+1, mr.lengths[k], len(gm.hst))
+		}
+
+		results[gm.st]++
+	}
+
+	if results[whiteWin] != mr.whiteWins || results[blackWin] != mr.blackWins || results[stalemate] != mr.stalemates {
+		t.Errorf("records replay to %d-%d-%d, results are %d-%d-%d", results[whiteWin], results[blackWin], results[stalemate], mr.whiteWins, mr.blackWins, mr.stalemates)
+	}
+
+	var buf bytes.Buffer
+	if err := writeMatchJSON(&buf, mr); err != nil {
+		t.Fatal(err)
+	}
+
+	var mf matchFile
+	if err := json.Unmarshal(buf.Bytes(), &mf); err != nil {
+		t.Fatal(err)
+	}
+
+	if mf.Games != 50 || mf.WhiteWins != mr.whiteWins || mf.BlackWins != mr.blackWins || mf.Stalemates != mr.stalemates || mf.WhiteWinRate != mr.rate(mr.whiteWins) {
+		t.Errorf("JSON report %+v disagrees with results", mf)
+	}
+}
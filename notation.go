@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Squares are named by a file letter and a rank number, as in chess. Files are
+// lettered from the left starting with 'a' and ranks are numbered from white's
+// side starting with one, so white's pawns begin on rank one of every board.
+// Moves are written as the square moved from and the square moved to, joined by
+// '-' for moving forward and 'x' for capturing, such as b1-b2 or b2xc3.
+
+// squareName returns the name of the square at row i and column j of a board
+// with m rows.
+func squareName(i, j, m int) string {
+	return string(rune('a'+j)) + strconv.Itoa(m-i)
+}
+
+// parseSquare returns the row and column of a named square on an m-by-n board.
+func parseSquare(s string, m, n int) (int, int, error) {
+	if len(s) < 2 || s[0] < 'a' || 'z' < s[0] {
+		return 0, 0, fmt.Errorf("invalid square %q", s)
+	}
+
+	rank, err := strconv.Atoi(s[1:])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid square %q", s)
+	}
+
+	i, j := m-rank, int(s[0]-'a')
+	if i < 0 || m <= i || n <= j {
+		return 0, 0, fmt.Errorf("square %q is not on a %dx%d board", s, m, n)
+	}
+
+	return i, j, nil
+}
+
+// target returns the row and column a pawn option moves a pawn to in a state.
+// Panics if the state is neither white nor black turn.
+func (po *pawnOpt) target(st state) (int, int) {
+	var dm, dn int // Row and column direction of the move
+	switch st {
+	case whiteTurn:
+		dm = -1
+		switch po.act {
+		case captureLeft:
+			dn = -1
+		case captureRight:
+			dn = 1
+		}
+	case blackTurn:
+		dm = 1
+		switch po.act {
+		case captureLeft:
+			dn = 1
+		case captureRight:
+			dn = -1
+		}
+	default:
+		panic("target: state is neither white nor black turn")
+	}
+
+	return po.m + dm, po.n + dn
+}
+
+// moveNotation returns the notation of a pawn option selected in a state on a
+// board with m rows.
+func moveNotation(po *pawnOpt, st state, m int) string {
+	sep := "-"
+	if po.act != forward {
+		sep = "x"
+	}
+
+	i, j := po.target(st)
+	return squareName(po.m, po.n, m) + sep + squareName(i, j, m)
+}
+
+// parseMove returns the available pawn option at a board and state that a move
+// in notation represents.
+func parseMove(s string, brd board, st state) (*pawnOpt, error) {
+	s = strings.TrimSpace(s)
+	k := strings.IndexAny(s, "-x")
+	if k < 0 {
+		return nil, fmt.Errorf("invalid move %q", s)
+	}
+
+	m, n := len(brd), len(brd[0])
+	i0, j0, err := parseSquare(s[:k], m, n)
+	if err != nil {
+		return nil, err
+	}
+
+	i1, j1, err := parseSquare(s[k+1:], m, n)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, po := range availPawnOpts(brd, st) {
+		if i, j := po.target(st); po.m == i0 && po.n == j0 && i == i1 && j == j1 {
+			return po, nil
+		}
+	}
+
+	return nil, fmt.Errorf("illegal move %q", s)
+}
+
+// Positions are written as the rows of the board from black's side to white's
+// side separated by '/', followed by a space and the side to move. Each row
+// lists its squares from file a, with 'w' for a white pawn, 'b' for a black pawn,
+// and a number for a run of empty squares. The start of a 3x3 game is written
+// bbb/3/www w.
+
+// formatPosition returns the notation of a board and state. Panics if the state
+// is neither white nor black turn.
+func formatPosition(brd board, st state) string {
+	var bldr strings.Builder
+	for i := range brd {
+		if 0 < i {
+			bldr.WriteByte('/')
+		}
+
+		var spaces int // Number of empty squares not yet written
+		for _, p := range brd[i] {
+			if p == space {
+				spaces++
+				continue
+			}
+
+			if 0 < spaces {
+				bldr.WriteString(strconv.Itoa(spaces))
+				spaces = 0
+			}
+
+			bldr.WriteByte(byte(p))
+		}
+
+		if 0 < spaces {
+			bldr.WriteString(strconv.Itoa(spaces))
+		}
+	}
+
+	bldr.WriteByte(' ')
+	bldr.WriteByte(byte(sideOf(st)))
+	return bldr.String()
+}
+
+// parsePosition returns the board and state a position in notation represents.
+func parsePosition(s string) (board, state, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return nil, 0, fmt.Errorf("invalid position %q: expected rows and side to move", s)
+	}
+
+	var st state
+	switch fields[1] {
+	case string(whiteSide):
+		st = whiteTurn
+	case string(blackSide):
+		st = blackTurn
+	default:
+		return nil, 0, fmt.Errorf("invalid side to move %q", fields[1])
+	}
+
+	rows := strings.Split(fields[0], "/")
+	brd := make(board, 0, len(rows))
+	for _, r := range rows {
+		row := make([]pawn, 0, len(r))
+		for k := 0; k < len(r); k++ {
+			switch c := r[k]; {
+			case c == byte(whitePawn) || c == byte(blackPawn):
+				row = append(row, pawn(c))
+			case '0' < c && c <= '9':
+				k0 := k
+				for k+1 < len(r) && '0' <= r[k+1] && r[k+1] <= '9' {
+					k++
+				}
+
+				spaces, _ := strconv.Atoi(r[k0 : k+1])
+				for ; 0 < spaces; spaces-- {
+					row = append(row, space)
+				}
+			default:
+				return nil, 0, fmt.Errorf("invalid square %q in row %q", c, r)
+			}
+		}
+
+		if 0 < len(brd) && len(row) != len(brd[0]) {
+			return nil, 0, fmt.Errorf("row %q has %d squares, expected %d", r, len(row), len(brd[0]))
+		}
+
+		brd = append(brd, row)
+	}
+
+	if len(brd) < 3 || len(brd[0]) < 3 {
+		return nil, 0, fmt.Errorf("position %q is smaller than 3x3", s)
+	}
+
+	return brd, st, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import "sort"
+
+// positionGrade compares the pawn options an auto player selects at a position
+// with perfect play.
+type positionGrade struct {
+	psn       *position // Position graded
+	best      solution  // Solution of the position with perfect play
+	bestPo    *pawnOpt  // First optimal pawn option
+	greedy    *pawnOpt  // Pawn option of highest weight
+	greedySol solution  // Solution of selecting the pawn option of highest weight
+	optProb   float64   // Probability the auto player selects a pawn option preserving the best result
+}
+
+// optimal returns true if selecting the pawn option of highest weight preserves
+// the result of the position with perfect play.
+func (pg *positionGrade) optimal() bool {
+	return pg.greedySol.res == pg.best.res
+}
+
+// severity returns how many results worse selecting the pawn option of highest
+// weight is than perfect play: zero, one, or two.
+func (pg *positionGrade) severity() int {
+	return int(pg.best.res) - int(pg.greedySol.res)
+}
+
+// outcomeProbs is the probability of each outcome of a game from a side's
+// perspective.
+type outcomeProbs struct {
+	win  float64 // Probability the side wins
+	draw float64 // Probability of stalemate
+	loss float64 // Probability the side loses
+}
+
+// score returns the expected fraction of points earned, counting a win as one
+// point and a stalemate as half a point.
+func (op outcomeProbs) score() float64 {
+	return op.win + op.draw/2
+}
+
+// add the probabilities of another set of outcomes, scaled by a probability.
+func (op *outcomeProbs) add(other outcomeProbs, p float64) {
+	op.win += p * other.win
+	op.draw += p * other.draw
+	op.loss += p * other.loss
+}
+
+// optimalityReport grades an auto player against perfect play.
+type optimalityReport struct {
+	grades   []*positionGrade // Grades of each position with pawn options, worst first
+	optimal  int              // Number of positions the pawn option of highest weight is optimal at
+	expected outcomeProbs     // Outcome of the auto player's policy against a perfect opponent
+}
+
+// percentOptimal returns the percentage of graded positions the pawn option of
+// highest weight is optimal at.
+func (rep *optimalityReport) percentOptimal() float64 {
+	if len(rep.grades) == 0 {
+		return 0
+	}
+
+	return 100 * float64(rep.optimal) / float64(len(rep.grades))
+}
+
+// gradeAutoPlayer grades each position an auto player has experienced against
+// the solutions of a solver, and computes the outcome of the auto player's
+// policy against a perfect opponent from the start of a game.
+func gradeAutoPlayer(ap *autoPlayer, slv *solver) *optimalityReport {
+	rep := &optimalityReport{grades: make([]*positionGrade, 0, len(ap.psns))}
+	for _, psn := range ap.sorted() {
+		if len(psn.pos) == 0 || psn.st != whiteTurn && psn.st != blackTurn {
+			continue
+		}
+
+		pg := gradePosition(psn, ap.temp, slv)
+		if pg.optimal() {
+			rep.optimal++
+		}
+
+		rep.grades = append(rep.grades, pg)
+	}
+
+	sort.SliceStable(rep.grades, func(i, j int) bool {
+		gi, gj := rep.grades[i], rep.grades[j]
+		if gi.severity() != gj.severity() {
+			return gj.severity() < gi.severity()
+		}
+
+		return gi.optProb < gj.optProb
+	})
+
+	rep.expected = newBestResponse(ap, opponentScore).outcome(newGrid(newBoard(ap.m, ap.n)), whiteTurn)
+	return rep
+}
+
+// gradePosition returns the grade of a position's pawn options at an
+// exploration temperature.
+func gradePosition(psn *position, temp float64, slv *solver) *positionGrade {
+	var (
+		pg       = &positionGrade{psn: psn}
+		g        = newGrid(psn.brd)
+		sols     = make([]solution, len(psn.pos))
+		probs, _ = policy(psn.pos, temp)
+	)
+
+	for i, po := range psn.pos {
+		sols[i] = slv.solvePawnOpt(g, psn.st, po)
+		if pg.bestPo == nil || sols[i].better(pg.best) {
+			pg.bestPo, pg.best = po, sols[i]
+		}
+
+		if pg.greedy == nil || pg.greedy.wght < po.wght {
+			pg.greedy, pg.greedySol = po, sols[i]
+		}
+	}
+
+	for i := range psn.pos {
+		if sols[i].res == pg.best.res {
+			pg.optProb += probs[i]
+		}
+	}
+
+	return pg
+}
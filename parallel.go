@@ -0,0 +1,184 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// defaultBatchGames is the number of games each worker plays per batch when a
+// train config does not set a batch size.
+const defaultBatchGames = 64
+
+// cloner is a player that can be copied, so that each worker training in
+// parallel plays against its own copy.
+type cloner interface {
+	clone() player
+}
+
+// policyPlayer selects pawn options with an auto player's weights without
+// altering the auto player, so any number of policy players may share one auto
+// player. Positions the auto player has not experienced are played as though
+// they had been inserted.
+type policyPlayer struct {
+	ap   *autoPlayer // Auto player whose weights are used
+	temp float64     // Exploration temperature
+	rnd  *rand.Rand  // Source of random selections
+}
+
+// chooseEvent returns an event representing an action taken on a given position.
+func (pp *policyPlayer) chooseEvent(psn *position) *event {
+	pos := psn.pos
+	if index := pp.ap.index(psn); 0 <= index {
+		pos = pp.ap.psns[index].pos
+	}
+
+	if po := choosePawnOpt(pos, pp.temp, pp.rnd); po != nil {
+		return &event{psn: copyPosition(psn), poSlc: copyPawnOpt(po)}
+	}
+
+	return &event{psn: copyPosition(psn)}
+}
+
+// clone returns a policy player sharing a policy player's auto player.
+func (pp *policyPlayer) clone() player {
+	return &policyPlayer{ap: pp.ap, temp: pp.temp, rnd: newRand(0)}
+}
+
+// reseed a policy player's random source.
+func (pp *policyPlayer) reseed(seed int64) {
+	pp.rnd = newRand(seed)
+}
+
+// fixedPlayer returns a player selecting pawn options as an auto player would
+// without inserting the positions it has not experienced, so playing against it
+// leaves it unaltered. Players that are not auto players are returned as they
+// are.
+func fixedPlayer(p player) player {
+	ap, ok := p.(*autoPlayer)
+	if !ok {
+		return p
+	}
+
+	return &policyPlayer{ap: ap, temp: ap.temp, rnd: ap.rnd}
+}
+
+// trainParallel trains an auto player on the games of a session numbered from
+// k0 up to, but not including, k1 with a number of workers playing games
+// concurrently. Games are played in batches against the auto player's weights
+// as they were at the start of the batch. Each worker collects its changes to
+// the weights and, after each batch, the changes are merged in worker order, so
+// the auto player depends only on the session's seed and number of workers. The
+// first worker is seeded as serial training is, so one worker playing batches of
+// one game trains the auto player as serial training does. Panics if the
+// opponent cannot be cloned.
+func (ap *autoPlayer) trainParallel(opp player, cfg trainConfig, k0, k1 int) {
+	c, ok := opp.(cloner)
+	if !ok {
+		panic("trainParallel: opponent cannot be cloned")
+	}
+
+	var (
+		pps  = make([]*policyPlayer, 0, cfg.workers) // Policy player of each worker
+		opps = make([]player, 0, cfg.workers)        // Opponent of each worker
+	)
+
+	for w := 0; w < cfg.workers; w++ {
+		pps = append(pps, &policyPlayer{ap: ap, rnd: newRand(deriveSeed(cfg.seed, int64(k0), int64(2*w)))})
+		opps = append(opps, c.clone())
+		reseedPlayer(opps[w], deriveSeed(cfg.seed, int64(k0), int64(2*w+1)))
+	}
+
+	batchGames := cfg.batchGames
+	if batchGames < 1 {
+		batchGames = defaultBatchGames * cfg.workers
+	}
+
+	deltas := make([]map[uint64]*position, cfg.workers) // Changes to weights collected by each worker
+	for b0 := k0; b0 < k1; b0 += batchGames {
+		b1 := b0 + batchGames
+		if k1 < b1 {
+			b1 = k1
+		}
+
+		var wg sync.WaitGroup
+		for w := 0; w < cfg.workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				deltas[w] = ap.playBatch(pps[w], opps[w], cfg, b0, b1, w)
+			}(w)
+		}
+
+		wg.Wait()
+		for w := range deltas {
+			ap.merge(deltas[w])
+		}
+	}
+}
+
+// playBatch plays the games of a batch numbered from b0 up to, but not including,
+// b1 that are assigned to a worker with its policy player and opponent, and
+// returns the changes to the weights of each position the auto player played
+// at, keyed by position. As in serial training, only those positions are
+// credited. The auto player is not altered.
+func (ap *autoPlayer) playBatch(pp *policyPlayer, opp player, cfg trainConfig, b0, b1, w int) map[uint64]*position {
+	var (
+		white, black player = pp, opp
+		delta               = make(map[uint64]*position)
+	)
+
+	if ap.sd == blackSide {
+		white, black = opp, pp
+	}
+
+	for k := b0 + w; k < b1; k += cfg.workers {
+		pp.temp = cfg.temperature.value(k, cfg.numGames)
+		gm := playGame(white, black, ap.m, ap.n)
+		for _, evnt := range gm.hst {
+			if key := evnt.psn.key(); evnt.psn.st == turnOf(ap.sd) && delta[key] == nil {
+				psn := copyPosition(evnt.psn)
+				for _, po := range psn.pos {
+					po.wght = 0
+				}
+
+				delta[key] = psn
+			}
+		}
+
+		creditEvents(gm.hst, gm.st, ap.sd, weight(cfg.learningRate.value(k, cfg.numGames)), cfg.credit, func(evnt *event, credit weight) {
+			if psn, ok := delta[evnt.psn.key()]; ok {
+				psn.credit(evnt.poSlc, credit)
+			}
+		})
+	}
+
+	return delta
+}
+
+// merge changes to the weights of positions into an auto player. Positions the
+// auto player has not experienced are inserted first, as serial training inserts
+// them when playing at them.
+func (ap *autoPlayer) merge(delta map[uint64]*position) {
+	keys := make([]uint64, 0, len(delta))
+	for key := range delta {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	for _, key := range keys {
+		index := ap.index(delta[key])
+		if index < 0 {
+			psn := copyPosition(delta[key])
+			for _, po := range psn.pos {
+				po.wght = 1 / weight(len(psn.pos))
+			}
+
+			index = ap.insert(psn)
+		}
+
+		for i, po := range ap.psns[index].pos {
+			po.wght += delta[key].pos[i].wght
+		}
+	}
+}
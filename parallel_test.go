@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+// TestParallelTraining checks that training in parallel with a seed and number of
+// workers always produces the same auto player, and that one worker playing
+// batches of one game produces the auto player serial training does, whether or
+// not the opponent's moves are credited.
+func TestParallelTraining(t *testing.T) {
+	for _, allMoves := range []bool{false, true} {
+		cfg := trainConfig{
+			numGames:     300,
+			seed:         5,
+			learningRate: constantSchedule(0.1),
+			temperature:  constantSchedule(1),
+			credit:       defaultCreditConfig(),
+		}
+
+		cfg.credit.allMoves = allMoves
+		train := func(workers, batchGames int) *autoPlayer {
+			ap := newAutoPlayer(blackSide, 4, 4, 1)
+			cfg.workers, cfg.batchGames = workers, batchGames
+			if workers == 1 {
+				ap.trainParallel(newRandomPlayer(0), cfg, 0, cfg.numGames)
+			} else {
+				ap.trainGames(newRandomPlayer(0), cfg, 0, cfg.numGames)
+			}
+
+			return ap
+		}
+
+		if a, b := train(4, 0), train(4, 0); !equalAgents(a, b) {
+			t.Errorf("all moves %t: parallel training with equal seeds differs", allMoves)
+		}
+
+		if a, b := train(1, 1), train(0, 0); !equalAgents(a, b) {
+			t.Errorf("all moves %t: one worker differs from serial training", allMoves)
+		}
+	}
+}
+
+// equalAgents returns true if two auto players have experienced the same
+// positions and weight them equally.
+func equalAgents(a, b *autoPlayer) bool {
+	if len(a.psns) != len(b.psns) {
+		return false
+	}
+
+	for _, psn := range a.psns {
+		index := b.index(psn)
+		if index < 0 || !equalPositions(psn, b.psns[index]) {
+			return false
+		}
+
+		for i, po := range psn.pos {
+			if po.wght != b.psns[index].pos[i].wght {
+				return false
+			}
+		}
+	}
+
+	return true
+}
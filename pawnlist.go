@@ -0,0 +1,111 @@
+package main
+
+import "sort"
+
+// pawnList is the set of squares holding a side's pawns in increasing order. The
+// square at row i and column j of an m-by-n board is i*n+j, so walking a pawn
+// list visits pawns in the same order as scanning the board row by row.
+type pawnList []int
+
+// newPawnLists returns the pawn lists of white and black on a board.
+func newPawnLists(brd board) (pawnList, pawnList) {
+	var (
+		n      = len(brd[0])
+		whites = make(pawnList, 0, n)
+		blacks = make(pawnList, 0, n)
+	)
+
+	for i := range brd {
+		for j, p := range brd[i] {
+			switch p {
+			case whitePawn:
+				whites = append(whites, i*n+j)
+			case blackPawn:
+				blacks = append(blacks, i*n+j)
+			}
+		}
+	}
+
+	return whites, blacks
+}
+
+// insert a square into a pawn list.
+func (pl pawnList) insert(sq int) pawnList {
+	k := sort.SearchInts(pl, sq)
+	pl = append(pl, 0)
+	copy(pl[k+1:], pl[k:])
+	pl[k] = sq
+	return pl
+}
+
+// remove a square from a pawn list. Panics if the square is not in the list.
+func (pl pawnList) remove(sq int) pawnList {
+	k := sort.SearchInts(pl, sq)
+	if len(pl) <= k || pl[k] != sq {
+		panic("remove: square not in pawn list")
+	}
+
+	return append(pl[:k], pl[k+1:]...)
+}
+
+// pawnOpts returns the set of pawn options available to the side to move in a
+// game. Only the pawns of the side to move are visited, and pawn options are
+// generated in the same order and with the same weights as availPawnOpts.
+func (gm *game) pawnOpts() pawnOpts {
+	var (
+		m, n = len(gm.brd), len(gm.brd[0])
+		pos  = make(pawnOpts, 0, 4)
+	)
+
+	switch gm.st {
+	case whiteTurn:
+		for _, sq := range gm.whites {
+			i, j := sq/n, sq%n
+			if i == 0 {
+				continue
+			}
+
+			if gm.brd[i-1][j] == space {
+				pos = append(pos, &pawnOpt{m: i, n: j, act: forward})
+			}
+
+			if 0 < j && gm.brd[i-1][j-1] == blackPawn {
+				pos = append(pos, &pawnOpt{m: i, n: j, act: captureLeft})
+			}
+
+			if j+1 < n && gm.brd[i-1][j+1] == blackPawn {
+				pos = append(pos, &pawnOpt{m: i, n: j, act: captureRight})
+			}
+		}
+	case blackTurn:
+		for _, sq := range gm.blacks {
+			i, j := sq/n, sq%n
+			if i+1 == m {
+				continue
+			}
+
+			if gm.brd[i+1][j] == space {
+				pos = append(pos, &pawnOpt{m: i, n: j, act: forward})
+			}
+
+			if j+1 < n && gm.brd[i+1][j+1] == whitePawn {
+				pos = append(pos, &pawnOpt{m: i, n: j, act: captureLeft})
+			}
+
+			if 0 < j && gm.brd[i+1][j-1] == whitePawn {
+				pos = append(pos, &pawnOpt{m: i, n: j, act: captureRight})
+			}
+		}
+	}
+
+	wght := weight(1)
+	if 1 < len(pos) {
+		wght = 1 / weight(len(pos))
+	}
+
+	for _, po := range pos {
+		po.wght = wght
+	}
+
+	return pos
+}
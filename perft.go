@@ -0,0 +1,96 @@
+package main
+
+// perftCounts counts the leaves of a game tree searched to a depth and the ways
+// games end within it. Leaves are the positions reached at the depth and the
+// positions where a game ended sooner. A stalemate takes a ply, as it does in a
+// game's history, so it is counted when the side to move has no pawn options
+// with plies left to search.
+type perftCounts struct {
+	leaves     int // Number of leaves
+	whiteWins  int // Number of games won by white
+	blackWins  int // Number of games won by black
+	stalemates int // Number of games ending in stalemate
+}
+
+// add another set of counts to a set of counts.
+func (pc *perftCounts) add(other perftCounts) {
+	pc.leaves += other.leaves
+	pc.whiteWins += other.whiteWins
+	pc.blackWins += other.blackWins
+	pc.stalemates += other.stalemates
+}
+
+// perft returns the counts of the game tree searched a number of plies from a
+// game's current position. The game is returned to its current position.
+func perft(gm *game, depth int) perftCounts {
+	return perftWith(gm, depth, (*game).pawnOpts)
+}
+
+// perftWith returns the counts of the game tree searched a number of plies from a
+// game's current position, generating pawn options with a function of the game.
+func perftWith(gm *game, depth int, gen func(gm *game) pawnOpts) perftCounts {
+	switch {
+	case gm.st == whiteWin:
+		return perftCounts{leaves: 1, whiteWins: 1}
+	case gm.st == blackWin:
+		return perftCounts{leaves: 1, blackWins: 1}
+	case depth == 0:
+		return perftCounts{leaves: 1}
+	}
+
+	pos := gen(gm)
+	if len(pos) == 0 {
+		return perftCounts{leaves: 1, stalemates: 1}
+	}
+
+	var pc perftCounts
+	for _, po := range pos {
+		gm.move(&event{poSlc: po})
+		pc.add(perftWith(gm, depth-1, gen))
+		gm.undo()
+	}
+
+	return pc
+}
+
+// perftGrid returns the counts of the game tree searched a number of plies from a
+// grid and state. It counts the same tree as perft using only the grid's game
+// logic, so the two may be compared to check a grid representation.
+func perftGrid(g grid, st state, depth int) perftCounts {
+	switch {
+	case st == whiteWin:
+		return perftCounts{leaves: 1, whiteWins: 1}
+	case st == blackWin:
+		return perftCounts{leaves: 1, blackWins: 1}
+	case depth == 0:
+		return perftCounts{leaves: 1}
+	}
+
+	pos := g.pawnOpts(st)
+	if len(pos) == 0 {
+		return perftCounts{leaves: 1, stalemates: 1}
+	}
+
+	var pc perftCounts
+	for _, po := range pos {
+		child, childSt := g.apply(po, st)
+		pc.add(perftGrid(child, childSt, depth-1))
+	}
+
+	return pc
+}
+
+// divide returns the counts of the game tree searched a number of plies from a
+// game's current position below each available pawn option, in the order of the
+// pawn options.
+func divide(gm *game, depth int) (pawnOpts, []perftCounts) {
+	pos := gm.pawnOpts()
+	pcs := make([]perftCounts, 0, len(pos))
+	for _, po := range pos {
+		gm.move(&event{poSlc: po})
+		pcs = append(pcs, perft(gm, depth-1))
+		gm.undo()
+	}
+
+	return pos, pcs
+}
@@ -0,0 +1,89 @@
+package main
+
+import "testing"
+
+// perftReference is a set of positions with the counts their game trees are
+// known to have at a depth.
+var perftReference = []struct {
+	psn   string      // Position in notation
+	depth int         // Number of plies searched
+	pc    perftCounts // Known counts
+}{
+	{psn: "bbb/3/www w", depth: 1, pc: perftCounts{leaves: 3}},
+	{psn: "bbb/3/www w", depth: 2, pc: perftCounts{leaves: 10}},
+	{psn: "bbb/3/www w", depth: 10, pc: perftCounts{leaves: 134, whiteWins: 58, blackWins: 62, stalemates: 14}},
+	{psn: "bbbb/4/wwww w", depth: 10, pc: perftCounts{leaves: 3610, whiteWins: 2076, blackWins: 1406, stalemates: 128}},
+	{psn: "bbbb/4/4/wwww w", depth: 16, pc: perftCounts{leaves: 2311588, whiteWins: 1330482, blackWins: 915206, stalemates: 46830}},
+	{psn: "bbbbb/5/5/5/wwwww w", depth: 8, pc: perftCounts{leaves: 428138, whiteWins: 724, blackWins: 3460}},
+	{psn: "bbbbbb/6/6/6/6/wwwwww w", depth: 6, pc: perftCounts{leaves: 46770}},
+	{psn: "bbbbbbbb/8/8/8/8/8/8/wwwwwwww w", depth: 6, pc: perftCounts{leaves: 262144}},
+	{psn: "3/b1b/w1w b", depth: 4, pc: perftCounts{leaves: 1, stalemates: 1}},
+	{psn: "b1b1/1w2/2w1/w3 b", depth: 8, pc: perftCounts{leaves: 64, whiteWins: 39, blackWins: 19, stalemates: 6}},
+}
+
+// shortPerftLeaves is the greatest number of leaves of a reference position
+// counted in short mode.
+const shortPerftLeaves = 100000
+
+// TestPerft checks the counts of each reference position with the pawn lists and
+// board scanning move generators of a game, a board, and a bitboard if the board
+// fits in one.
+func TestPerft(t *testing.T) {
+	for _, ref := range perftReference {
+		if testing.Short() && shortPerftLeaves < ref.pc.leaves {
+			continue
+		}
+
+		brd, st, err := parsePosition(ref.psn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reps := map[string]func() perftCounts{
+			"pawn lists": func() perftCounts { return perft(newGameAt(copyBoard(brd), st, cvc), ref.depth) },
+			"board scan": func() perftCounts {
+				return perftWith(newGameAt(copyBoard(brd), st, cvc), ref.depth, func(gm *game) pawnOpts { return availPawnOpts(gm.brd, gm.st) })
+			},
+			"board": func() perftCounts { return perftGrid(copyBoard(brd), st, ref.depth) },
+		}
+
+		if fitsBitboard(len(brd), len(brd[0])) {
+			reps["bitboard"] = func() perftCounts { return perftGrid(toBitboard(brd), st, ref.depth) }
+		}
+
+		for name, pc := range reps {
+			if got := pc(); got != ref.pc {
+				t.Errorf("%q at depth %d with %s: expected %+v, got %+v", ref.psn, ref.depth, name, ref.pc, got)
+			}
+		}
+	}
+}
+
+// TestDivide checks that the counts below each pawn option sum to the counts of
+// the position.
+func TestDivide(t *testing.T) {
+	for _, ref := range perftReference {
+		if shortPerftLeaves < ref.pc.leaves || ref.depth == 0 {
+			continue
+		}
+
+		brd, st, err := parsePosition(ref.psn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			gm     = newGameAt(brd, st, cvc)
+			_, pcs = divide(gm, ref.depth)
+			sum    perftCounts
+		)
+
+		for _, pc := range pcs {
+			sum.add(pc)
+		}
+
+		if len(pcs) != 0 && sum != ref.pc {
+			t.Errorf("%q at depth %d: expected %+v, got %+v", ref.psn, ref.depth, ref.pc, sum)
+		}
+	}
+}
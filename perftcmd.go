@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// perftCmd counts the leaves and outcomes of the game tree searched to a depth
+// from a position. With -divide, the counts below each pawn option are listed.
+func perftCmd(args []string) error {
+	var (
+		fs    = flag.NewFlagSet("perft", flag.ContinueOnError)
+		m     = fs.Int("m", 3, "number of rows")
+		n     = fs.Int("n", 3, "number of columns")
+		psn   = fs.String("position", "", "position in notation, such as \"bbb/3/www w\" (default the starting position)")
+		depth = fs.Int("depth", 4, "number of plies to search")
+		div   = fs.Bool("divide", false, "list the counts below each pawn option")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *depth < 0 {
+		return fmt.Errorf("perft: invalid depth %d", *depth)
+	}
+
+	var (
+		brd board
+		st  = whiteTurn
+	)
+
+	if *psn == "" {
+		if *m < 3 || *n < 3 {
+			return fmt.Errorf("perft: invalid dimensions %dx%d", *m, *n)
+		}
+
+		brd = newBoard(*m, *n)
+	} else {
+		var err error
+		if brd, st, err = parsePosition(*psn); err != nil {
+			return fmt.Errorf("perft: %v", err)
+		}
+	}
+
+	gm := newGameAt(brd, st, cvc)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "move\tleaves\twhite wins\tblack wins\tstalemates\t")
+	if *div && 0 < *depth && (st == whiteTurn || st == blackTurn) {
+		pos, pcs := divide(gm, *depth)
+		for i, po := range pos {
+			writePerftCounts(tw, moveNotation(po, st, len(brd)), pcs[i])
+		}
+	}
+
+	var pc perftCounts
+	t := timeIt(func() { pc = perft(gm, *depth) })
+	writePerftCounts(tw, "total", pc)
+	tw.Flush()
+
+	fmt.Printf("depth: %d\ntime:  %v\n", *depth, t.Round(time.Millisecond))
+	return nil
+}
+
+// writePerftCounts writes a row of perft counts.
+func writePerftCounts(tw *tabwriter.Writer, name string, pc perftCounts) {
+	fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t\n", name, pc.leaves, pc.whiteWins, pc.blackWins, pc.stalemates)
+}
+
+// timeIt returns the time taken to call a function.
+func timeIt(f func()) time.Duration {
+	start := time.Now()
+	f()
+	return time.Since(start)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// playCmd plays a number of games between two players and reports the outcomes.
+// Each game is seeded from the given seed, and the seed of each game is written
+// to its record.
+func playCmd(args []string) error {
+	var (
+		fs       = flag.NewFlagSet("play", flag.ContinueOnError)
+		m        = fs.Int("m", 3, "number of rows")
+		n        = fs.Int("n", 3, "number of columns")
+		white    = fs.String("white", "random", "white player (random, solver, search:depth, or agent file)")
+		black    = fs.String("black", "random", "black player (random, solver, search:depth, or agent file)")
+		numGames = fs.Int("games", 1, "number of games to play")
+		seed     = fs.Int64("seed", 0, "random seed (0 seeds from the clock)")
+		out      = fs.String("out", "", "file the game records are appended to")
+		ratings  = fs.String("ratings", "", "ratings file updated with the games played")
+		format   = fs.String("format", "text", "report format (text, json, or csv)")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *m < 3 || *n < 3 {
+		return fmt.Errorf("play: invalid dimensions %dx%d", *m, *n)
+	}
+
+	if *ratings != "" && *white == *black {
+		return fmt.Errorf("play: %s cannot be rated playing itself", *white)
+	}
+
+	report, err := matchReporter(*format)
+	if err != nil {
+		return fmt.Errorf("play: %v", err)
+	}
+
+	if *seed == 0 {
+		*seed = clockSeed()
+	}
+
+	wp, err := parsePlayer(*white, whiteSide, *m, *n, *seed)
+	if err != nil {
+		return fmt.Errorf("play: white: %v", err)
+	}
+
+	bp, err := parsePlayer(*black, blackSide, *m, *n, *seed)
+	if err != nil {
+		return fmt.Errorf("play: black: %v", err)
+	}
+
+	mr, err := playMatch(wp, bp, *white, *black, *m, *n, *numGames, *seed)
+	if err != nil {
+		return fmt.Errorf("play: %v", err)
+	}
+
+	if *out != "" {
+		if err := writeRecords(*out, mr.records); err != nil {
+			return fmt.Errorf("play: %v", err)
+		}
+	}
+
+	if *ratings != "" {
+		grs, err := recordResults(mr.records)
+		if err != nil {
+			return fmt.Errorf("play: %v", err)
+		}
+
+		if err := rateGames(*ratings, *m, *n, standardRules, grs); err != nil {
+			return fmt.Errorf("play: %v", err)
+		}
+	}
+
+	if err := report(os.Stdout, mr); err != nil {
+		return fmt.Errorf("play: %v", err)
+	}
+
+	return nil
+}
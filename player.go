@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// player is anything that can select an event at a position.
+type player interface {
+	chooseEvent(psn *position) *event
+}
+
+// randomPlayer selects an available pawn option uniformly at random.
+type randomPlayer struct {
+	rnd *rand.Rand // Source of random selections
+}
+
+// newRandomPlayer returns a random player with a random source seeded with a
+// seed.
+func newRandomPlayer(seed int64) *randomPlayer {
+	return &randomPlayer{rnd: newRand(seed)}
+}
+
+// chooseEvent returns an event selecting a random pawn option at a position.
+func (rp *randomPlayer) chooseEvent(psn *position) *event {
+	return &event{psn: copyPosition(psn), poSlc: randPawnOpt(psn, rp.rnd)}
+}
+
+// clone returns a copy of a random player.
+func (rp *randomPlayer) clone() player {
+	return newRandomPlayer(0)
+}
+
+// reseed a random player's random source.
+func (rp *randomPlayer) reseed(seed int64) {
+	rp.rnd = newRand(seed)
+}
+
+// turnOf returns the state indicating it is a side's turn to move.
+func turnOf(sd side) state {
+	switch sd {
+	case whiteSide:
+		return whiteTurn
+	case blackSide:
+		return blackTurn
+	default:
+		panic("turnOf: invalid side")
+	}
+}
+
+// sideOf returns the side to move in a state.
+func sideOf(st state) side {
+	switch st {
+	case whiteTurn:
+		return whiteSide
+	case blackTurn:
+		return blackSide
+	default:
+		panic("sideOf: state is neither white nor black turn")
+	}
+}
+
+// sideName returns the name of a side.
+func sideName(sd side) string {
+	switch sd {
+	case whiteSide:
+		return "white"
+	case blackSide:
+		return "black"
+	default:
+		panic("sideName: invalid side")
+	}
+}
+
+// copyAutoPlayer returns a deep copy of an auto player.
+func copyAutoPlayer(ap *autoPlayer) *autoPlayer {
+	cpy := &autoPlayer{
+		sd:       ap.sd,
+		m:        ap.m,
+		n:        ap.n,
+		temp:     ap.temp,
+		seed:     ap.seed,
+		rnd:      newRand(ap.seed),
+		psns:     make([]*position, 0, len(ap.psns)),
+		keys:     make(map[uint64]int, len(ap.psns)),
+		sessions: append([]trainConfig(nil), ap.sessions...),
+	}
+
+	for i := range ap.psns {
+		cpy.add(copyPosition(ap.psns[i]))
+	}
+
+	return cpy
+}
+
+// randAutoPlayer returns a random auto player from a set (nil if the set is
+// empty).
+func randAutoPlayer(aps []*autoPlayer, rnd *rand.Rand) *autoPlayer {
+	if len(aps) == 0 {
+		return nil
+	}
+
+	return aps[rnd.Intn(len(aps))]
+}
+
+// parsePlayer returns the player described by a spec for an m-by-n board playing
+// a side. A spec is one of "random", "solver", "search:depth", or the path to a
+// saved auto player. Players with a random source are seeded with a seed.
+func parsePlayer(spec string, sd side, m, n int, seed int64) (player, error) {
+	switch {
+	case spec == "random":
+		return newRandomPlayer(seed), nil
+	case spec == "solver":
+		return newSolverPlayer(), nil
+	case strings.HasPrefix(spec, "search:"):
+		depth, err := strconv.Atoi(strings.TrimPrefix(spec, "search:"))
+		if err != nil || depth < 1 {
+			return nil, fmt.Errorf("parsePlayer: invalid search depth in %q", spec)
+		}
+
+		return newSearchPlayer(depth), nil
+	default:
+		ap, err := loadAutoPlayer(spec)
+		if err != nil {
+			return nil, fmt.Errorf("parsePlayer: %v", err)
+		}
+
+		if ap.m != m || ap.n != n {
+			return nil, fmt.Errorf("parsePlayer: %s plays on %dx%d boards, not %dx%d", spec, ap.m, ap.n, m, n)
+		}
+
+		if ap.sd != sd {
+			return nil, fmt.Errorf("parsePlayer: %s plays %q, not %q", spec, byte(ap.sd), byte(sd))
+		}
+
+		ap.reseed(seed)
+		return ap, nil
+	}
+}
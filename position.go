@@ -9,6 +9,7 @@ type position struct {
 	st  state    // State of the game
 	brd board    // Board position
 	pos pawnOpts // Available pawn options
+	hsh uint64   // Zobrist hash of the board and state; zero if not known
 }
 
 // String returns the formated representation of a position.
@@ -34,7 +35,7 @@ func (psn *position) toBytes() []byte {
 
 // copyPosition returns a copy of a postion.
 func copyPosition(psn *position) *position {
-	cpy := &position{brd: copyBoard(psn.brd), st: psn.st, pos: make(pawnOpts, 0, len(psn.pos))}
+	cpy := &position{brd: copyBoard(psn.brd), st: psn.st, pos: make(pawnOpts, 0, len(psn.pos)), hsh: psn.hsh}
 	for i := range psn.pos {
 		cpy.pos = append(cpy.pos, copyPawnOpt(psn.pos[i]))
 	}
@@ -42,6 +43,25 @@ func copyPosition(psn *position) *position {
 	return cpy
 }
 
+// credit a pawn option at a position. The pawn option's weight is increased by
+// the credit and the credit is taken evenly from the others, so the weights of a
+// position continue to sum to one.
+func (psn *position) credit(po *pawnOpt, credit weight) {
+	n := len(psn.pos)
+	if n < 2 {
+		return // Either zero or one pawn option to select; nothing to train on
+	}
+
+	for _, p := range psn.pos {
+		if equalPawnOpts(p, po) {
+			p.wght += credit
+			continue
+		}
+
+		p.wght -= credit / weight(n-1)
+	}
+}
+
 // equalPositions returns true if each field is equal and false if otherwise.
 func equalPositions(psn0, psn1 *position) bool {
 	switch {
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// Players are rated by the Glicko-2 system. A rating is accompanied by a
+// deviation, which is how uncertain the rating is, and a volatility, which is
+// how erratic the player's results are. Ratings are updated once per rating
+// period from all the games played in it, so a match or tournament is a rating
+// period. Ratings are kept separately for each board size and ruleset, as a
+// player's strength on one says little of its strength on another.
+
+// Glicko-2 constants
+const (
+	defaultRating     = 1500.0   // Rating of an unrated player
+	defaultDeviation  = 350.0    // Deviation of an unrated player
+	defaultVolatility = 0.06     // Volatility of an unrated player
+	glickoScale       = 173.7178 // Ratio of the Glicko scale to the Glicko-2 scale
+	glickoTau         = 0.5      // Constrains the change in volatility over time
+	glickoEpsilon     = 0.000001 // Convergence tolerance of the volatility
+
+	standardRules = "standard" // Stalemate ends the game without a winner
+)
+
+// rating is a player's rating in a pool.
+type rating struct {
+	Name       string  `json:"name"`
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+	Games      int     `json:"games"`
+}
+
+// ratingPool is the ratings of players on a board size under a ruleset.
+type ratingPool struct {
+	Rows    int       `json:"rows"`
+	Columns int       `json:"columns"`
+	Rules   string    `json:"rules"`
+	Players []*rating `json:"players"`
+}
+
+// ratingsFile is the persisted form of every rating pool.
+type ratingsFile struct {
+	Pools []*ratingPool `json:"pools"`
+}
+
+// gameResult is the outcome of a game between two named players.
+type gameResult struct {
+	white string  // Name of the white player
+	black string  // Name of the black player
+	score float64 // Points earned by white: one for a win, a half for a stalemate, and zero for a loss
+}
+
+// recordResults returns the results of recorded games. Games a player played
+// against itself are skipped, as they say nothing of its strength.
+func recordResults(recs []*record) ([]gameResult, error) {
+	grs := make([]gameResult, 0, len(recs))
+	for i, rec := range recs {
+		gr := gameResult{white: rec.White, black: rec.Black}
+		switch rec.Result {
+		case whiteWinResult:
+			gr.score = 1
+		case blackWinResult:
+			gr.score = 0
+		case stalemateResult:
+			gr.score = 0.5
+		default:
+			return nil, fmt.Errorf("record %d: invalid result %q", i+1, rec.Result)
+		}
+
+		if gr.white == "" || gr.black == "" {
+			return nil, fmt.Errorf("record %d: unnamed player", i+1)
+		}
+
+		if gr.white == gr.black {
+			continue
+		}
+
+		grs = append(grs, gr)
+	}
+
+	return grs, nil
+}
+
+// readRatings reads a ratings file from a path. A missing file has no ratings.
+func readRatings(path string) (*ratingsFile, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &ratingsFile{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("readRatings: %v", err)
+	}
+	defer f.Close()
+
+	var rf ratingsFile
+	if err := json.NewDecoder(f).Decode(&rf); err != nil {
+		return nil, fmt.Errorf("readRatings: %v", err)
+	}
+
+	return &rf, nil
+}
+
+// write a ratings file to a path. The file is written to a temporary path first
+// and then renamed, so an interrupted write never leaves a partial file behind.
+func (rf *ratingsFile) write(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("write: %v", err)
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rf); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("write: %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write: %v", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// pool returns the rating pool of a board size and ruleset, adding it if there is
+// none.
+func (rf *ratingsFile) pool(m, n int, rules string) *ratingPool {
+	for _, rp := range rf.Pools {
+		if rp.Rows == m && rp.Columns == n && rp.Rules == rules {
+			return rp
+		}
+	}
+
+	rp := &ratingPool{Rows: m, Columns: n, Rules: rules}
+	rf.Pools = append(rf.Pools, rp)
+	return rp
+}
+
+// rateGames updates the ratings of a board size and ruleset in a ratings file at
+// a path from the results of a rating period. The file is created if it does
+// not exist.
+func rateGames(path string, m, n int, rules string, grs []gameResult) error {
+	rf, err := readRatings(path)
+	if err != nil {
+		return fmt.Errorf("rateGames: %v", err)
+	}
+
+	rf.pool(m, n, rules).update(grs)
+	if err := rf.write(path); err != nil {
+		return fmt.Errorf("rateGames: %v", err)
+	}
+
+	return nil
+}
+
+// player returns the rating of a named player, adding an unrated player if there
+// is none.
+func (rp *ratingPool) player(name string) *rating {
+	for _, r := range rp.Players {
+		if r.Name == name {
+			return r
+		}
+	}
+
+	r := &rating{Name: name, Rating: defaultRating, Deviation: defaultDeviation, Volatility: defaultVolatility}
+	rp.Players = append(rp.Players, r)
+	return r
+}
+
+// update the ratings of a pool from the results of a rating period. Every player
+// is rated against its opponents' ratings from before the period. The
+// deviation of each player that did not play grows.
+func (rp *ratingPool) update(grs []gameResult) {
+	type opponent struct {
+		r     rating  // Opponent's rating before the period
+		score float64 // Points earned against the opponent
+	}
+
+	before := make(map[string]rating)
+	for _, gr := range grs {
+		before[gr.white] = *rp.player(gr.white)
+		before[gr.black] = *rp.player(gr.black)
+	}
+
+	opps := make(map[string][]opponent)
+	for _, gr := range grs {
+		opps[gr.white] = append(opps[gr.white], opponent{r: before[gr.black], score: gr.score})
+		opps[gr.black] = append(opps[gr.black], opponent{r: before[gr.white], score: 1 - gr.score})
+	}
+
+	for _, r := range rp.Players {
+		var (
+			mu  = (r.Rating - defaultRating) / glickoScale
+			phi = r.Deviation / glickoScale
+		)
+
+		if len(opps[r.Name]) == 0 {
+			r.Deviation = math.Min(math.Sqrt(phi*phi+r.Volatility*r.Volatility)*glickoScale, defaultDeviation)
+			continue
+		}
+
+		var v, delta float64 // Estimated variance and improvement
+		for _, opp := range opps[r.Name] {
+			var (
+				muj = (opp.r.Rating - defaultRating) / glickoScale
+				g   = glickoG(opp.r.Deviation / glickoScale)
+				e   = 1 / (1 + math.Exp(-g*(mu-muj)))
+			)
+
+			v += g * g * e * (1 - e)
+			delta += g * (opp.score - e)
+		}
+
+		v = 1 / v
+		sigma := glickoVolatility(phi, r.Volatility, v, v*delta)
+		phiStar := math.Sqrt(phi*phi + sigma*sigma)
+		phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+
+		r.Rating = (mu+phi*phi*delta)*glickoScale + defaultRating
+		r.Deviation = phi * glickoScale
+		r.Volatility = sigma
+		r.Games += len(opps[r.Name])
+	}
+}
+
+// glickoG returns how much a game against an opponent counts given the
+// opponent's deviation on the Glicko-2 scale.
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// glickoVolatility returns the volatility of a player after a rating period by
+// the Illinois algorithm, given the player's deviation on the Glicko-2 scale,
+// volatility, estimated variance, and estimated improvement.
+func glickoVolatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(glickoTau*glickoTau)
+	}
+
+	A, B := a, 0.0
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*glickoTau) < 0 {
+			k++
+		}
+
+		B = a - k*glickoTau
+	}
+
+	fA, fB := f(A), f(B)
+	for glickoEpsilon < math.Abs(B-A) {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
+
+// writeRatings writes a table of the ratings of a pool, highest first.
+func writeRatings(w io.Writer, rp *ratingPool) error {
+	rs := append(make([]*rating, 0, len(rp.Players)), rp.Players...)
+	sort.SliceStable(rs, func(i, j int) bool { return rs[j].Rating < rs[i].Rating })
+
+	fmt.Fprintf(w, "%dx%d, %s rules\n", rp.Rows, rp.Columns, rp.Rules)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "rank\tname\trating\tdeviation\tvolatility\tgames\t")
+	for k, r := range rs {
+		fmt.Fprintf(tw, "%d\t%s\t%.0f\t±%.0f\t%.4f\t%d\t\n", k+1, r.Name, r.Rating, r.Deviation, r.Volatility, r.Games)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// ratingsCmd reports the ratings in a ratings file. Given files of game records,
+// the ratings are first updated from the games in them, each file being a
+// rating period.
+func ratingsCmd(args []string) error {
+	var (
+		fs    = flag.NewFlagSet("ratings", flag.ContinueOnError)
+		path  = fs.String("file", "ratings.json", "ratings file")
+		m     = fs.Int("m", 0, "number of rows of the pool reported (0 reports every pool)")
+		n     = fs.Int("n", 0, "number of columns of the pool reported (0 reports every pool)")
+		rules = fs.String("rules", standardRules, "ruleset of the games rated")
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: hexapawn ratings [flags] [records...]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	for _, recPath := range fs.Args() {
+		recs, err := readRecords(recPath)
+		if err != nil {
+			return fmt.Errorf("ratings: %v", err)
+		}
+
+		// Records may hold games on several board sizes, each rated in its own pool
+		var (
+			byDims = make(map[[2]int][]*record)
+			dims   = make([][2]int, 0, 1)
+		)
+
+		for _, rec := range recs {
+			d := [2]int{rec.Rows, rec.Columns}
+			if _, ok := byDims[d]; !ok {
+				dims = append(dims, d)
+			}
+
+			byDims[d] = append(byDims[d], rec)
+		}
+
+		for _, d := range dims {
+			grs, err := recordResults(byDims[d])
+			if err != nil {
+				return fmt.Errorf("ratings: %s: %v", recPath, err)
+			}
+
+			if err := rateGames(*path, d[0], d[1], *rules, grs); err != nil {
+				return fmt.Errorf("ratings: %v", err)
+			}
+		}
+	}
+
+	rf, err := readRatings(*path)
+	if err != nil {
+		return fmt.Errorf("ratings: %v", err)
+	}
+
+	var written bool
+	for _, rp := range rf.Pools {
+		if (*m != 0 && rp.Rows != *m) || (*n != 0 && rp.Columns != *n) || rp.Rules != *rules {
+			continue
+		}
+
+		if written {
+			fmt.Println()
+		}
+
+		written = true
+
+		if err := writeRatings(os.Stdout, rp); err != nil {
+			return fmt.Errorf("ratings: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Results as written in game records
+const (
+	whiteWinResult   = "1-0"
+	blackWinResult   = "0-1"
+	stalemateResult  = "1/2-1/2"
+	unfinishedResult = "*" // Game is not over
+)
+
+// record is the persisted form of a game. A game is reproduced exactly by
+// replaying its moves, or by playing the same players again with the seed.
+// Games that did not begin at the start record the position they began at, and
+// reviewed games record annotations of their blunders and inaccuracies.
+type record struct {
+	Rows    int      `json:"rows"`
+	Columns int      `json:"columns"`
+	Start   string   `json:"start,omitempty"`
+	Seed    int64    `json:"seed"`
+	White   string   `json:"white,omitempty"`
+	Black   string   `json:"black,omitempty"`
+	Moves   []string `json:"moves"`
+	Result  string   `json:"result"`
+
+	Annotations []annotation `json:"annotations,omitempty"`
+}
+
+// annotation is a comment on a move of a game record.
+type annotation struct {
+	Ply     int    `json:"ply"`     // Number of the move commented on, starting at one
+	Class   string `json:"class"`   // Blunder or inaccuracy
+	Comment string `json:"comment"` // Explanation of the class
+	Best    string `json:"best"`    // Best move available
+}
+
+// newRecord returns the record of a game played by named players with a seed.
+func newRecord(gm *game, seed int64, white, black string) *record {
+	rec := &record{
+		Rows:    len(gm.brd),
+		Columns: len(gm.brd[0]),
+		Seed:    seed,
+		White:   white,
+		Black:   black,
+		Moves:   make([]string, 0, len(gm.hst)),
+	}
+
+	start, st := gm.brd, gm.st
+	if 0 < len(gm.hst) {
+		start, st = gm.hst[0].psn.brd, gm.hst[0].psn.st
+	}
+
+	if st != whiteTurn || !equalBoards(start, newBoard(rec.Rows, rec.Columns)) {
+		rec.Start = formatPosition(start, st)
+	}
+
+	for _, evnt := range gm.hst {
+		if evnt.poSlc != nil {
+			rec.Moves = append(rec.Moves, moveNotation(evnt.poSlc, evnt.psn.st, rec.Rows))
+		}
+	}
+
+	switch gm.st {
+	case whiteWin:
+		rec.Result = whiteWinResult
+	case blackWin:
+		rec.Result = blackWinResult
+	case stalemate:
+		rec.Result = stalemateResult
+	default:
+		rec.Result = unfinishedResult
+	}
+
+	return rec
+}
+
+// game returns the game a record represents by replaying its moves.
+func (rec *record) game() (*game, error) {
+	if rec.Rows < 3 || rec.Columns < 3 {
+		return nil, fmt.Errorf("invalid dimensions %dx%d", rec.Rows, rec.Columns)
+	}
+
+	gm := newGame(rec.Rows, rec.Columns, cvc)
+	if rec.Start != "" {
+		brd, st, err := parsePosition(rec.Start)
+		if err != nil {
+			return nil, fmt.Errorf("start: %v", err)
+		}
+
+		if len(brd) != rec.Rows || len(brd[0]) != rec.Columns {
+			return nil, fmt.Errorf("start: expected %dx%d board, got %dx%d", rec.Rows, rec.Columns, len(brd), len(brd[0]))
+		}
+
+		gm = newGameAt(brd, st, cvc)
+	}
+
+	for i, mv := range rec.Moves {
+		if gm.st != whiteTurn && gm.st != blackTurn {
+			return nil, fmt.Errorf("move %d: game is over", i+1)
+		}
+
+		psn := gm.position()
+		po, err := parseMove(mv, psn.brd, psn.st)
+		if err != nil {
+			return nil, fmt.Errorf("move %d: %v", i+1, err)
+		}
+
+		gm.move(&event{psn: copyPosition(psn), poSlc: copyPawnOpt(po)})
+	}
+
+	if rec.Result == stalemateResult && (gm.st == whiteTurn || gm.st == blackTurn) {
+		psn := gm.position()
+		if len(psn.pos) != 0 {
+			return nil, fmt.Errorf("stalemate recorded with moves available")
+		}
+
+		gm.move(&event{psn: copyPosition(psn)})
+	}
+
+	if rec.Result == unfinishedResult {
+		if gm.st != whiteTurn && gm.st != blackTurn {
+			return nil, fmt.Errorf("game recorded as unfinished is over")
+		}
+
+		return gm, nil
+	}
+
+	if want := map[string]state{whiteWinResult: whiteWin, blackWinResult: blackWin, stalemateResult: stalemate}[rec.Result]; gm.st != want {
+		return nil, fmt.Errorf("result %q does not match the moves played", rec.Result)
+	}
+
+	return gm, nil
+}
+
+// writeRecords appends records to a file, one record per line.
+func writeRecords(path string, recs []*record) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("writeRecords: %v", err)
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, rec := range recs {
+		if err := enc.Encode(rec); err != nil {
+			f.Close()
+			return fmt.Errorf("writeRecords: %v", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("writeRecords: %v", err)
+	}
+
+	return f.Close()
+}
+
+// readRecords reads records from a file written by writeRecords.
+func readRecords(path string) ([]*record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("readRecords: %v", err)
+	}
+	defer f.Close()
+
+	var recs []*record
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		rec := &record{}
+		if err := dec.Decode(rec); err != nil {
+			return nil, fmt.Errorf("readRecords: record %d: %v", len(recs)+1, err)
+		}
+
+		recs = append(recs, rec)
+	}
+
+	return recs, nil
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+// Boards are rendered with white's side at the bottom. With coordinates, files
+// are labeled below the board and ranks to its left, matching the move
+// notation. The raster formats label coordinates with a small bitmap font, as
+// the standard library has no font rendering.
+
+// arrow marks a move from one square to another.
+type arrow struct {
+	i0, j0 int // Row and column moved from
+	i1, j1 int // Row and column moved to
+}
+
+// renderConfig determines how boards are rendered.
+type renderConfig struct {
+	scale  int     // Pixels per square
+	coords bool    // Label files and ranks
+	last   *arrow  // Highlight the squares of the last move if not nil
+	arrows []arrow // Arrows drawn over the board
+}
+
+// frame is a board to be rendered with the move that reached it.
+type frame struct {
+	brd  board  // Pawns
+	st   state  // State
+	last *arrow // Last move; nil if none
+}
+
+// Palette indices of the colors boards are rendered in
+const (
+	backgroundColor uint8 = iota
+	lightColor
+	darkColor
+	highlightColor
+	whitePawnColor
+	blackPawnColor
+	outlineColor
+	arrowColor
+	textColor
+)
+
+// renderPalette is the colors boards are rendered in.
+var renderPalette = color.Palette{
+	backgroundColor: color.RGBA{0xff, 0xff, 0xff, 0xff},
+	lightColor:      color.RGBA{0xf0, 0xd9, 0xb5, 0xff},
+	darkColor:       color.RGBA{0xb5, 0x88, 0x63, 0xff},
+	highlightColor:  color.RGBA{0xf6, 0xf6, 0x69, 0xff},
+	whitePawnColor:  color.RGBA{0xfa, 0xfa, 0xfa, 0xff},
+	blackPawnColor:  color.RGBA{0x20, 0x20, 0x20, 0xff},
+	outlineColor:    color.RGBA{0x00, 0x00, 0x00, 0xff},
+	arrowColor:      color.RGBA{0xd0, 0x30, 0x30, 0xff},
+	textColor:       color.RGBA{0x40, 0x40, 0x40, 0xff},
+}
+
+// newArrow returns the arrow of a pawn option selected in a state.
+func newArrow(po *pawnOpt, st state) arrow {
+	i1, j1 := po.target(st)
+	return arrow{i0: po.m, j0: po.n, i1: i1, j1: j1}
+}
+
+// parseArrow returns the arrow of a move in notation on an m-by-n board. Unlike
+// parseMove, the move need not be available.
+func parseArrow(s string, m, n int) (arrow, error) {
+	i := strings.IndexAny(s, "-x")
+	if i < 0 {
+		return arrow{}, fmt.Errorf("invalid move %q", s)
+	}
+
+	i0, j0, err := parseSquare(s[:i], m, n)
+	if err != nil {
+		return arrow{}, err
+	}
+
+	i1, j1, err := parseSquare(s[i+1:], m, n)
+	if err != nil {
+		return arrow{}, err
+	}
+
+	return arrow{i0: i0, j0: j0, i1: i1, j1: j1}, nil
+}
+
+// historyFrames returns a frame for the position before each event of a history
+// and one for the position after the last event.
+func historyFrames(hst history) []frame {
+	if len(hst) == 0 {
+		return nil
+	}
+
+	gm := newGameAt(copyBoard(hst[0].psn.brd), hst[0].psn.st, cvc)
+	frs := append(make([]frame, 0, len(hst)+1), frame{brd: copyBoard(gm.brd), st: gm.st})
+	for _, evnt := range hst {
+		var last *arrow
+		if evnt.poSlc != nil {
+			a := newArrow(evnt.poSlc, gm.st)
+			last = &a
+		}
+
+		gm.move(&event{psn: evnt.psn, poSlc: evnt.poSlc})
+		frs = append(frs, frame{brd: copyBoard(gm.brd), st: gm.st, last: last})
+	}
+
+	return frs
+}
+
+// margin returns the number of pixels left of and below a board for coordinates.
+func (cfg renderConfig) margin() int {
+	if cfg.coords {
+		return cfg.scale / 2
+	}
+
+	return 0
+}
+
+// size returns the width and height in pixels of a rendered m-by-n board.
+func (cfg renderConfig) size(m, n int) (int, int) {
+	return n*cfg.scale + cfg.margin(), m*cfg.scale + cfg.margin()
+}
+
+// writeSVG writes a board as an SVG image.
+func writeSVG(w io.Writer, brd board, cfg renderConfig) error {
+	var (
+		bldr       = strings.Builder{}
+		m, n       = len(brd), len(brd[0])
+		s, mg      = float64(cfg.scale), float64(cfg.margin())
+		wd, ht     = cfg.size(m, n)
+		hex        = func(c uint8) string { return svgColor(renderPalette[c]) }
+		center     = func(i, j int) (float64, float64) { return mg + (float64(j)+0.5)*s, (float64(i) + 0.5) * s }
+		lastSquare = func(i, j int) bool {
+			return cfg.last != nil && (i == cfg.last.i0 && j == cfg.last.j0 || i == cfg.last.i1 && j == cfg.last.j1)
+		}
+	)
+
+	fmt.Fprintf(&bldr, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", wd, ht, wd, ht)
+	fmt.Fprintf(&bldr, "<defs><marker id=\"head\" markerWidth=\"4\" markerHeight=\"4\" refX=\"2\" refY=\"2\" orient=\"auto\"><path d=\"M0,0 L4,2 L0,4 z\" fill=\"%s\"/></marker></defs>\n", hex(arrowColor))
+	fmt.Fprintf(&bldr, "<rect width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", wd, ht, hex(backgroundColor))
+	for i := range brd {
+		for j, p := range brd[i] {
+			c := squareColor(i, j, m)
+			if lastSquare(i, j) {
+				c = highlightColor
+			}
+
+			fmt.Fprintf(&bldr, "<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" fill=\"%s\"/>\n", mg+float64(j)*s, float64(i)*s, s, s, hex(c))
+			if p != space {
+				fill := hex(whitePawnColor)
+				if p == blackPawn {
+					fill = hex(blackPawnColor)
+				}
+
+				x, y := center(i, j)
+				fmt.Fprintf(&bldr, "<circle cx=\"%g\" cy=\"%g\" r=\"%g\" fill=\"%s\" stroke=\"%s\" stroke-width=\"%g\"/>\n", x, y, 0.35*s, fill, hex(outlineColor), math.Max(s/32, 1))
+			}
+		}
+	}
+
+	for _, a := range cfg.arrows {
+		x0, y0 := center(a.i0, a.j0)
+		x1, y1 := center(a.i1, a.j1)
+		fmt.Fprintf(&bldr, "<line x1=\"%g\" y1=\"%g\" x2=\"%g\" y2=\"%g\" stroke=\"%s\" stroke-width=\"%g\" stroke-opacity=\"0.8\" marker-end=\"url(#head)\"/>\n", x0, y0, x0+(x1-x0)*0.8, y0+(y1-y0)*0.8, hex(arrowColor), s/10)
+	}
+
+	if cfg.coords {
+		for j := 0; j < n; j++ {
+			fmt.Fprintf(&bldr, "<text x=\"%g\" y=\"%g\" font-family=\"sans-serif\" font-size=\"%g\" text-anchor=\"middle\" dominant-baseline=\"middle\" fill=\"%s\">%c</text>\n", mg+(float64(j)+0.5)*s, float64(m)*s+mg/2, mg*0.6, hex(textColor), 'a'+j)
+		}
+
+		for i := 0; i < m; i++ {
+			fmt.Fprintf(&bldr, "<text x=\"%g\" y=\"%g\" font-family=\"sans-serif\" font-size=\"%g\" text-anchor=\"middle\" dominant-baseline=\"middle\" fill=\"%s\">%d</text>\n", mg/2, (float64(i)+0.5)*s, mg*0.6, hex(textColor), m-i)
+		}
+	}
+
+	bldr.WriteString("</svg>\n")
+	_, err := io.WriteString(w, bldr.String())
+	return err
+}
+
+// svgColor returns the SVG form of a color.
+func svgColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// squareColor returns the palette index of the square at row i and column j of a
+// board with m rows. Square a1 is dark, as in chess.
+func squareColor(i, j, m int) uint8 {
+	if (m-1-i+j)%2 == 0 {
+		return darkColor
+	}
+
+	return lightColor
+}
+
+// drawBoard returns a board drawn as an image.
+func drawBoard(brd board, cfg renderConfig) *image.Paletted {
+	var (
+		m, n   = len(brd), len(brd[0])
+		s, mg  = cfg.scale, cfg.margin()
+		wd, ht = cfg.size(m, n)
+		img    = image.NewPaletted(image.Rect(0, 0, wd, ht), renderPalette)
+	)
+
+	for i := range brd {
+		for j, p := range brd[i] {
+			c := squareColor(i, j, m)
+			if cfg.last != nil && (i == cfg.last.i0 && j == cfg.last.j0 || i == cfg.last.i1 && j == cfg.last.j1) {
+				c = highlightColor
+			}
+
+			fillRect(img, mg+j*s, i*s, s, s, c)
+			if p != space {
+				fill := whitePawnColor
+				if p == blackPawn {
+					fill = blackPawnColor
+				}
+
+				cx, cy, r := float64(mg)+(float64(j)+0.5)*float64(s), (float64(i)+0.5)*float64(s), 0.35*float64(s)
+				fillCircle(img, cx, cy, r, outlineColor)
+				fillCircle(img, cx, cy, r-math.Max(float64(s)/32, 1), fill)
+			}
+		}
+	}
+
+	for _, a := range cfg.arrows {
+		x0, y0 := float64(mg)+(float64(a.j0)+0.5)*float64(s), (float64(a.i0)+0.5)*float64(s)
+		x1, y1 := float64(mg)+(float64(a.j1)+0.5)*float64(s), (float64(a.i1)+0.5)*float64(s)
+		drawArrow(img, x0, y0, x1, y1, float64(s))
+	}
+
+	if cfg.coords {
+		px := mg / 8 // Pixels per font dot
+		if px < 1 {
+			px = 1
+		}
+
+		for j := 0; j < n; j++ {
+			drawText(img, string(rune('a'+j)), mg+j*s+s/2, m*s+mg/2, px)
+		}
+
+		for i := 0; i < m; i++ {
+			drawText(img, fmt.Sprint(m-i), mg/2, i*s+s/2, px)
+		}
+	}
+
+	return img
+}
+
+// writePNG writes a board as a PNG image.
+func writePNG(w io.Writer, brd board, cfg renderConfig) error {
+	return png.Encode(w, drawBoard(brd, cfg))
+}
+
+// writeGIF writes frames as an animated GIF, showing each frame for a delay in
+// hundredths of a second and the last frame for three times as long. The last
+// move of each frame is highlighted.
+func writeGIF(w io.Writer, frs []frame, cfg renderConfig, delay int) error {
+	anim := &gif.GIF{}
+	for k, fr := range frs {
+		cfg.last = fr.last
+		anim.Image = append(anim.Image, drawBoard(fr.brd, cfg))
+		if k == len(frs)-1 {
+			anim.Delay = append(anim.Delay, 3*delay)
+		} else {
+			anim.Delay = append(anim.Delay, delay)
+		}
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+// writeFilmstrip writes frames as a single PNG image, with a number of frames per
+// row. The last move of each frame is highlighted.
+func writeFilmstrip(w io.Writer, frs []frame, cfg renderConfig, perRow int) error {
+	if len(frs) == 0 {
+		return fmt.Errorf("writeFilmstrip: no frames")
+	}
+
+	if perRow < 1 || len(frs) < perRow {
+		perRow = len(frs)
+	}
+
+	var (
+		gap    = cfg.scale / 4
+		fw, fh = cfg.size(len(frs[0].brd), len(frs[0].brd[0]))
+		rows   = (len(frs) + perRow - 1) / perRow
+		img    = image.NewPaletted(image.Rect(0, 0, perRow*(fw+gap)+gap, rows*(fh+gap)+gap), renderPalette)
+	)
+
+	for k, fr := range frs {
+		cfg.last = fr.last
+		sub := drawBoard(fr.brd, cfg)
+		x0, y0 := gap+(k%perRow)*(fw+gap), gap+(k/perRow)*(fh+gap)
+		for y := 0; y < fh; y++ {
+			copy(img.Pix[(y0+y)*img.Stride+x0:(y0+y)*img.Stride+x0+fw], sub.Pix[y*sub.Stride:y*sub.Stride+fw])
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// fillRect fills a rectangle of an image with a color.
+func fillRect(img *image.Paletted, x, y, wd, ht int, c uint8) {
+	r := image.Rect(x, y, x+wd, y+ht).Intersect(img.Rect)
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			img.SetColorIndex(px, py, c)
+		}
+	}
+}
+
+// fillCircle fills a circle of an image with a color.
+func fillCircle(img *image.Paletted, cx, cy, r float64, c uint8) {
+	for py := int(cy - r); py <= int(cy+r); py++ {
+		for px := int(cx - r); px <= int(cx+r); px++ {
+			if dx, dy := float64(px)+0.5-cx, float64(py)+0.5-cy; dx*dx+dy*dy <= r*r {
+				img.SetColorIndex(px, py, c)
+			}
+		}
+	}
+}
+
+// drawArrow draws an arrow from one point of an image toward another, sized for
+// squares of a number of pixels.
+func drawArrow(img *image.Paletted, x0, y0, x1, y1, s float64) {
+	var (
+		dx, dy = x1 - x0, y1 - y0
+		length = math.Hypot(dx, dy)
+	)
+
+	if length == 0 {
+		return
+	}
+
+	ux, uy := dx/length, dy/length
+	head := s / 3
+	tipX, tipY := x0+dx*0.85, y0+dy*0.85
+	baseX, baseY := tipX-ux*head, tipY-uy*head
+
+	// Shaft as discs along the line
+	for t := 0.0; t <= 1; t += 1 / length {
+		fillCircle(img, x0+(baseX-x0)*t, y0+(baseY-y0)*t, s/20, arrowColor)
+	}
+
+	// Head as a triangle
+	var (
+		ax, ay = baseX - uy*head/2, baseY + ux*head/2
+		bx, by = baseX + uy*head/2, baseY - ux*head/2
+		minX   = math.Min(tipX, math.Min(ax, bx))
+		maxX   = math.Max(tipX, math.Max(ax, bx))
+		minY   = math.Min(tipY, math.Min(ay, by))
+		maxY   = math.Max(tipY, math.Max(ay, by))
+		cross  = func(px, py, qx, qy, rx, ry float64) float64 { return (qx-px)*(ry-py) - (qy-py)*(rx-px) }
+	)
+
+	for py := int(minY); py <= int(maxY); py++ {
+		for px := int(minX); px <= int(maxX); px++ {
+			x, y := float64(px)+0.5, float64(py)+0.5
+			d0, d1, d2 := cross(tipX, tipY, ax, ay, x, y), cross(ax, ay, bx, by, x, y), cross(bx, by, tipX, tipY, x, y)
+			if (0 <= d0 && 0 <= d1 && 0 <= d2) || (d0 <= 0 && d1 <= 0 && d2 <= 0) {
+				img.SetColorIndex(px, py, arrowColor)
+			}
+		}
+	}
+}
+
+// drawText draws text centered on a point of an image in the bitmap font, each
+// dot of the font a number of pixels square.
+func drawText(img *image.Paletted, text string, cx, cy, px int) {
+	var (
+		wd = (4*len(text) - 1) * px
+		x0 = cx - wd/2
+		y0 = cy - 5*px/2
+	)
+
+	for k, r := range text {
+		glyph, ok := bitmapFont[r]
+		if !ok {
+			continue
+		}
+
+		for gy, row := range glyph {
+			for gx, dot := range row {
+				if dot == '#' {
+					fillRect(img, x0+(4*k+gx)*px, y0+gy*px, px, px, textColor)
+				}
+			}
+		}
+	}
+}
+
+// bitmapFont is a three by five dot font of the digits and lowercase letters.
+var bitmapFont = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'a': {".#.", "#.#", "###", "#.#", "#.#"},
+	'b': {"##.", "#.#", "##.", "#.#", "##."},
+	'c': {"###", "#..", "#..", "#..", "###"},
+	'd': {"##.", "#.#", "#.#", "#.#", "##."},
+	'e': {"###", "#..", "##.", "#..", "###"},
+	'f': {"###", "#..", "##.", "#..", "#.."},
+	'g': {"###", "#..", "#.#", "#.#", "###"},
+	'h': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'i': {"###", ".#.", ".#.", ".#.", "###"},
+	'j': {"..#", "..#", "..#", "#.#", "###"},
+	'k': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'l': {"#..", "#..", "#..", "#..", "###"},
+	'm': {"#.#", "###", "###", "#.#", "#.#"},
+	'n': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'o': {"###", "#.#", "#.#", "#.#", "###"},
+	'p': {"###", "#.#", "###", "#..", "#.."},
+	'q': {"###", "#.#", "#.#", "###", "..#"},
+	'r': {"##.", "#.#", "##.", "#.#", "#.#"},
+	's': {"###", "#..", "###", "..#", "###"},
+	't': {"###", ".#.", ".#.", ".#.", ".#."},
+	'u': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'v': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'w': {"#.#", "#.#", "###", "###", "#.#"},
+	'x': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'z': {"###", "..#", ".#.", "#..", "###"},
+}
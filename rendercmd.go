@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// renderCmd renders a position, or a recorded game, as an image: an SVG or PNG of
+// a single position, or an animated GIF or PNG filmstrip of a whole game. A
+// single position may also be rendered as text for a terminal.
+func renderCmd(args []string) error {
+	var (
+		fs      = flag.NewFlagSet("render", flag.ContinueOnError)
+		m       = fs.Int("m", 3, "number of rows")
+		n       = fs.Int("n", 3, "number of columns")
+		psn     = fs.String("position", "", "position in notation, such as \"bbb/3/www w\" (default the starting position)")
+		recPath = fs.String("records", "", "file of game records to render a game from")
+		gameNum = fs.Int("game", 1, "number of the recorded game rendered")
+		ply     = fs.Int("ply", -1, "number of plies into a recorded game of the position rendered (negative renders the final position)")
+		format  = fs.String("format", "svg", "image format (svg, png, gif, strip, or text)")
+		scale   = fs.Int("scale", 48, "pixels per square")
+		coords  = fs.Bool("coords", true, "label files and ranks")
+		arrows  = fs.String("arrows", "", "comma separated moves drawn as arrows, such as \"b1-b2,a1xb2\"")
+		last    = fs.String("last", "", "move highlighted as the last move")
+		delay   = fs.Int("delay", 80, "hundredths of a second each frame of a gif is shown")
+		perRow  = fs.Int("per-row", 6, "frames per row of a filmstrip")
+		out     = fs.String("out", "", "file the image is written to (default standard output)")
+		unicode = fs.Bool("unicode", false, "draw pawns as chess pawn glyphs in text")
+		color   = fs.Bool("color", false, "color pawns and the last move with ANSI escape codes in text")
+		flip    = fs.Bool("flip", false, "show the board from black's side in text")
+		compact = fs.Bool("compact", false, "render text on a single line")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch *format {
+	case "svg", "png", "text":
+	case "gif", "strip":
+		if *arrows != "" || *last != "" {
+			return fmt.Errorf("render: -arrows and -last apply to single positions, not the %s format", *format)
+		}
+	default:
+		return fmt.Errorf("render: unknown format %q", *format)
+	}
+
+	if *scale < 8 {
+		return fmt.Errorf("render: invalid scale %d", *scale)
+	}
+
+	var frs []frame
+	if *recPath != "" {
+		recs, err := readRecords(*recPath)
+		if err != nil {
+			return fmt.Errorf("render: %v", err)
+		}
+
+		if *gameNum < 1 || len(recs) < *gameNum {
+			return fmt.Errorf("render: %s has no game %d", *recPath, *gameNum)
+		}
+
+		gm, err := recs[*gameNum-1].game()
+		if err != nil {
+			return fmt.Errorf("render: game %d: %v", *gameNum, err)
+		}
+
+		if frs = historyFrames(gm.hst); len(frs) == 0 {
+			frs = []frame{{brd: gm.brd, st: gm.st}}
+		}
+	} else {
+		fr := frame{st: whiteTurn}
+		if *psn == "" {
+			if *m < 3 || *n < 3 {
+				return fmt.Errorf("render: invalid dimensions %dx%d", *m, *n)
+			}
+
+			fr.brd = newBoard(*m, *n)
+		} else {
+			var err error
+			if fr.brd, fr.st, err = parsePosition(*psn); err != nil {
+				return fmt.Errorf("render: %v", err)
+			}
+		}
+
+		frs = []frame{fr}
+	}
+
+	if len(frs) <= *ply {
+		if *recPath == "" {
+			return errors.New("render: -ply requires -records")
+		}
+
+		return fmt.Errorf("render: game %d has %d plies", *gameNum, len(frs)-1)
+	}
+
+	// The position rendered as a single image
+	fr := frs[len(frs)-1]
+	if 0 <= *ply {
+		fr = frs[*ply]
+	}
+
+	cfg := renderConfig{scale: *scale, coords: *coords, last: fr.last}
+	rows, cols := len(fr.brd), len(fr.brd[0])
+	if *last != "" {
+		a, err := parseArrow(*last, rows, cols)
+		if err != nil {
+			return fmt.Errorf("render: %v", err)
+		}
+
+		// Every move changes rows, which text rendering relies on to bracket
+		// the squares of the last move
+		if a.i0 == a.i1 {
+			return fmt.Errorf("render: the last move %q does not change rows", *last)
+		}
+
+		cfg.last = &a
+	}
+
+	if *arrows != "" {
+		for _, s := range strings.Split(*arrows, ",") {
+			a, err := parseArrow(strings.TrimSpace(s), rows, cols)
+			if err != nil {
+				return fmt.Errorf("render: %v", err)
+			}
+
+			cfg.arrows = append(cfg.arrows, a)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("render: %v", err)
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	var err error
+	switch *format {
+	case "svg":
+		err = writeSVG(w, fr.brd, cfg)
+	case "png":
+		err = writePNG(w, fr.brd, cfg)
+	case "gif":
+		err = writeGIF(w, frs, cfg, *delay)
+	case "strip":
+		err = writeFilmstrip(w, frs, cfg, *perRow)
+	case "text":
+		tcfg := termConfig{coords: *coords, unicode: *unicode, color: *color, flip: *flip, compact: *compact, last: cfg.last}
+		gm := newGameAt(fr.brd, fr.st, cvc)
+		_, err = io.WriteString(w, gm.render(tcfg)+"\n")
+	}
+
+	if err != nil {
+		return fmt.Errorf("render: %v", err)
+	}
+
+	return nil
+}
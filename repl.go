@@ -0,0 +1,543 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// repl is an interactive shell over a game. Agents may be loaded or trained for
+// either side, and positions are analyzed with a solver or search.
+type repl struct {
+	gm     *game                  // Game being played
+	agents map[side]*autoPlayer   // Agent of each side; nil if none
+	slv    *solver                // Solver of the current board size
+	term   termConfig             // How boards are shown
+	seed   int64                  // Seed of agents created by training
+	hist   []string               // Commands entered, in order
+	out    io.Writer              // Output of commands
+	cmds   map[string]replCommand // Commands by name
+}
+
+// replCommand is a command of a repl.
+type replCommand struct {
+	usage string                             // Arguments the command takes
+	help  string                             // What the command does
+	run   func(r *repl, args []string) error // Runs the command with its arguments
+}
+
+// newRepl returns a repl with a new game on an m-by-n board.
+func newRepl(m, n int, seed int64, out io.Writer) *repl {
+	r := &repl{
+		gm:     newGame(m, n, pvp),
+		agents: make(map[side]*autoPlayer),
+		slv:    newSolver(),
+		term:   termConfig{coords: true},
+		seed:   seed,
+		out:    out,
+	}
+
+	r.cmds = map[string]replCommand{
+		"eval":    {usage: "", help: "evaluate the position", run: (*repl).eval},
+		"go":      {usage: "", help: "let the agent of the side to move, or the solver, move", run: (*repl).goMove},
+		"help":    {usage: "", help: "list the commands", run: (*repl).help},
+		"hint":    {usage: "", help: "explain each legal move and how likely the agent is to play it", run: (*repl).hint},
+		"history": {usage: "", help: "list the commands entered; !n runs command n again", run: (*repl).history},
+		"load":    {usage: "agent <file> | game <file> [number]", help: "load an agent, or a recorded game", run: (*repl).load},
+		"move":    {usage: "<move>", help: "make a move, such as b1-b2 or b2xc3", run: (*repl).move},
+		"moves":   {usage: "", help: "list the legal moves", run: (*repl).moves},
+		"new":     {usage: "[rows columns]", help: "start a new game", run: (*repl).newGame},
+		"review":  {usage: "", help: "flag the blunders and inaccuracies of the moves played", run: (*repl).review},
+		"save":    {usage: "game <file>", help: "append the game's record to a file", run: (*repl).save},
+		"set":     {usage: "<coords|unicode|color|flip|compact> <on|off>", help: "change how boards are shown", run: (*repl).set},
+		"setup":   {usage: "<position>", help: "set up a position, such as bbb/3/www w", run: (*repl).setup},
+		"show":    {usage: "", help: "show the board and the moves played", run: (*repl).show},
+		"train":   {usage: "<games>", help: "train an agent for each side on random games", run: (*repl).train},
+		"undo":    {usage: "", help: "take back the last move", run: (*repl).undo},
+	}
+
+	return r
+}
+
+// loop reads commands from an input until it ends or a quit command is entered,
+// writing a prompt before each. Errors are written and do not end the loop.
+func (r *repl) loop(in io.Reader) {
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(r.out, "hexapawn> ")
+		if !sc.Scan() {
+			fmt.Fprintln(r.out)
+			return
+		}
+
+		line := strings.TrimSpace(sc.Text())
+		if line == "quit" || line == "exit" {
+			return
+		}
+
+		if err := r.exec(line); err != nil {
+			fmt.Fprintf(r.out, "error: %v\n", err)
+		}
+	}
+}
+
+// exec runs a line of input. A line of the form !n runs the nth command entered
+// again.
+func (r *repl) exec(line string) error {
+	if line == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(line, "!") {
+		k, err := strconv.Atoi(line[1:])
+		if err != nil || k < 1 || len(r.hist) < k {
+			return fmt.Errorf("no command %s in history", line[1:])
+		}
+
+		line = r.hist[k-1]
+		fmt.Fprintln(r.out, line)
+	}
+
+	r.hist = append(r.hist, line)
+	fields := strings.Fields(line)
+	cmd, ok := r.cmds[fields[0]]
+	if !ok {
+		if len(fields) == 1 && strings.ContainsAny(fields[0], "-x") {
+			return r.move(fields) // A move entered without the move command
+		}
+
+		return fmt.Errorf("unknown command %q; type help for a list of commands", fields[0])
+	}
+
+	return cmd.run(r, fields[1:])
+}
+
+// help lists the commands.
+func (r *repl) help(args []string) error {
+	names := make([]string, 0, len(r.cmds))
+	for name := range r.cmds {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	tw := tabwriter.NewWriter(r.out, 0, 8, 2, ' ', 0)
+	for _, name := range names {
+		cmd := r.cmds[name]
+		fmt.Fprintf(tw, "  %s\t%s\n", strings.TrimSpace(name+" "+cmd.usage), cmd.help)
+	}
+
+	fmt.Fprintf(tw, "  %s\t%s\n", "quit", "leave")
+	return tw.Flush()
+}
+
+// history lists the commands entered.
+func (r *repl) history(args []string) error {
+	for k, line := range r.hist {
+		fmt.Fprintf(r.out, "%4d  %s\n", k+1, line)
+	}
+
+	return nil
+}
+
+// newGame starts a new game, on a new board size if one is given.
+func (r *repl) newGame(args []string) error {
+	m, n := r.gm.dims()
+	switch len(args) {
+	case 0:
+	case 2:
+		var err error
+		if m, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("invalid number of rows %q", args[0])
+		}
+
+		if n, err = strconv.Atoi(args[1]); err != nil {
+			return fmt.Errorf("invalid number of columns %q", args[1])
+		}
+
+		if m < 3 || n < 3 {
+			return fmt.Errorf("boards must be at least 3x3, not %dx%d", m, n)
+		}
+	default:
+		return fmt.Errorf("usage: new [rows columns]")
+	}
+
+	r.start(newBoard(m, n), whiteTurn)
+	return r.show(nil)
+}
+
+// setup sets up a position.
+func (r *repl) setup(args []string) error {
+	brd, st, err := parsePosition(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	if st != whiteTurn && st != blackTurn {
+		return fmt.Errorf("position must have a side to move")
+	}
+
+	r.start(brd, st)
+	r.endStalemate()
+	return r.show(nil)
+}
+
+// start a game from a board and state. Agents of another board size are
+// dropped.
+func (r *repl) start(brd board, st state) {
+	m, n := r.gm.dims()
+	if len(brd) != m || len(brd[0]) != n {
+		for sd, ap := range r.agents {
+			if ap.m != len(brd) || ap.n != len(brd[0]) {
+				fmt.Fprintf(r.out, "dropped the %s agent, which plays on %dx%d boards\n", sideName(sd), ap.m, ap.n)
+				delete(r.agents, sd)
+			}
+		}
+	}
+
+	r.gm = newGameAt(brd, st, pvp)
+}
+
+// dims returns the number of rows and columns of a game's board.
+func (gm *game) dims() (int, int) {
+	return len(gm.brd), len(gm.brd[0])
+}
+
+// show the board and the moves played.
+func (r *repl) show(args []string) error {
+	cfg := r.term
+	if k := r.lastMove(); 0 <= k {
+		a := newArrow(r.gm.hst[k].poSlc, r.gm.hst[k].psn.st)
+		cfg.last = &a
+	}
+
+	fmt.Fprintln(r.out, r.gm.render(cfg))
+	if mvs := r.playedMoves(); mvs != "" {
+		fmt.Fprintln(r.out, "moves:", mvs)
+	}
+
+	return nil
+}
+
+// lastMove returns the index in the history of the last event selecting a pawn
+// option, or -1 if there is none.
+func (r *repl) lastMove() int {
+	for k := len(r.gm.hst) - 1; 0 <= k; k-- {
+		if r.gm.hst[k].poSlc != nil {
+			return k
+		}
+	}
+
+	return -1
+}
+
+// playedMoves returns the moves played, numbered by turn.
+func (r *repl) playedMoves() string {
+	var (
+		mvs  = make([]string, 0, len(r.gm.hst))
+		m, _ = r.gm.dims()
+		turn = 1
+	)
+
+	for _, evnt := range r.gm.hst {
+		if evnt.poSlc == nil {
+			continue
+		}
+
+		mv := moveNotation(evnt.poSlc, evnt.psn.st, m)
+		switch {
+		case evnt.psn.st == whiteTurn:
+			mv = fmt.Sprintf("%d. %s", turn, mv)
+		case len(mvs) == 0:
+			mv = fmt.Sprintf("%d... %s", turn, mv)
+		}
+
+		if evnt.psn.st == blackTurn {
+			turn++
+		}
+
+		mvs = append(mvs, mv)
+	}
+
+	return strings.Join(mvs, " ")
+}
+
+// moves lists the legal moves.
+func (r *repl) moves(args []string) error {
+	if r.over() {
+		return fmt.Errorf("the game is over: %s", stateName(r.gm.st))
+	}
+
+	m, _ := r.gm.dims()
+	mvs := make([]string, 0, 8)
+	for _, po := range r.gm.pawnOpts() {
+		mvs = append(mvs, moveNotation(po, r.gm.st, m))
+	}
+
+	fmt.Fprintln(r.out, strings.Join(mvs, " "))
+	return nil
+}
+
+// over returns true if the game is over.
+func (r *repl) over() bool {
+	return r.gm.st != whiteTurn && r.gm.st != blackTurn
+}
+
+// move makes a move.
+func (r *repl) move(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: move <move>")
+	}
+
+	if r.over() {
+		return fmt.Errorf("the game is over: %s", stateName(r.gm.st))
+	}
+
+	po, err := parseMove(args[0], r.gm.brd, r.gm.st)
+	if err != nil {
+		return err
+	}
+
+	r.play(po)
+	return r.show(nil)
+}
+
+// play a pawn option, ending the game in stalemate if the opponent is left with
+// no pawn options.
+func (r *repl) play(po *pawnOpt) {
+	psn := copyPosition(r.gm.position())
+	r.gm.move(&event{psn: psn, poSlc: copyPawnOpt(po)})
+	r.endStalemate()
+}
+
+// endStalemate ends the game in stalemate if the side to move has no pawn
+// options.
+func (r *repl) endStalemate() {
+	if !r.over() && len(r.gm.pawnOpts()) == 0 {
+		r.gm.move(&event{psn: copyPosition(r.gm.position())})
+	}
+}
+
+// undo takes back the last move.
+func (r *repl) undo(args []string) error {
+	if r.lastMove() < 0 {
+		return fmt.Errorf("no moves to take back")
+	}
+
+	if r.gm.st == stalemate {
+		r.gm.undo()
+	}
+
+	r.gm.undo()
+	return r.show(nil)
+}
+
+// goMove lets the agent of the side to move, or the solver if there is none,
+// make a move.
+func (r *repl) goMove(args []string) error {
+	if r.over() {
+		return fmt.Errorf("the game is over: %s", stateName(r.gm.st))
+	}
+
+	var p player = &solverPlayer{slv: r.slv}
+	if ap := r.agents[sideOf(r.gm.st)]; ap != nil {
+		p = ap
+	}
+
+	evnt := p.chooseEvent(r.gm.position())
+	if evnt.poSlc == nil {
+		return fmt.Errorf("no move was chosen")
+	}
+
+	m, _ := r.gm.dims()
+	fmt.Fprintln(r.out, moveNotation(evnt.poSlc, r.gm.st, m))
+	r.play(evnt.poSlc)
+	return r.show(nil)
+}
+
+// hint lists each legal move with the probability the agent of the side to move
+// selects it, its result with perfect play, and what it does. On boards too large
+// to solve, the best move by search is suggested instead of results.
+func (r *repl) hint(args []string) error {
+	if r.over() {
+		return fmt.Errorf("the game is over: %s", stateName(r.gm.st))
+	}
+
+	var (
+		m, n = r.gm.dims()
+		slv  = r.slv
+	)
+
+	if analysisSolveSquares < m*n {
+		slv = nil
+	}
+
+	if err := writeHints(r.out, hints(r.gm.brd, r.gm.st, r.agents[sideOf(r.gm.st)], slv), r.gm.st, m); err != nil {
+		return err
+	}
+
+	if slv == nil {
+		evnt := newSearchPlayer(analysisDepth).chooseEvent(r.gm.position())
+		fmt.Fprintf(r.out, "search: %s\n", moveNotation(evnt.poSlc, r.gm.st, m))
+	}
+
+	return nil
+}
+
+// eval evaluates the position with the solver, or with search on large boards.
+func (r *repl) eval(args []string) error {
+	if r.over() {
+		fmt.Fprintln(r.out, stateName(r.gm.st))
+		return nil
+	}
+
+	m, n := r.gm.dims()
+	g := newGrid(r.gm.brd)
+	if m*n <= analysisSolveSquares {
+		fmt.Fprintf(r.out, "%s: %v\n", sideName(sideOf(r.gm.st)), r.slv.solve(g, r.gm.st))
+		return nil
+	}
+
+	sp := newSearchPlayer(analysisDepth)
+	score := sp.negamax(g, r.gm.st, analysisDepth, -winScore<<1, winScore<<1)
+	fmt.Fprintf(r.out, "%s: %d at depth %d\n", sideName(sideOf(r.gm.st)), score, analysisDepth)
+	return nil
+}
+
+// review lists the moves played with their assessments, flagging blunders and
+// inaccuracies, by solver or, on large boards, by search.
+func (r *repl) review(args []string) error {
+	if r.lastMove() < 0 {
+		return fmt.Errorf("no moves to review")
+	}
+
+	rvr := newSolvingReviewer(r.slv)
+	if m, n := r.gm.dims(); analysisSolveSquares < m*n {
+		rvr = newSearchingReviewer(analysisDepth)
+	}
+
+	return writeReview(r.out, rvr.review(r.gm.hst))
+}
+
+// load loads an agent, or a recorded game.
+func (r *repl) load(args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "agent":
+		ap, err := loadAutoPlayer(args[1])
+		if err != nil {
+			return err
+		}
+
+		if m, n := r.gm.dims(); ap.m != m || ap.n != n {
+			return fmt.Errorf("%s plays on %dx%d boards, not %dx%d", args[1], ap.m, ap.n, m, n)
+		}
+
+		r.agents[ap.sd] = ap
+		fmt.Fprintf(r.out, "loaded a %s agent with %d positions\n", sideName(ap.sd), len(ap.psns))
+		return nil
+	case (len(args) == 2 || len(args) == 3) && args[0] == "game":
+		recs, err := readRecords(args[1])
+		if err != nil {
+			return err
+		}
+
+		k := len(recs)
+		if len(args) == 3 {
+			if k, err = strconv.Atoi(args[2]); err != nil {
+				return fmt.Errorf("invalid game number %q", args[2])
+			}
+		}
+
+		if k < 1 || len(recs) < k {
+			return fmt.Errorf("%s has no game %d", args[1], k)
+		}
+
+		gm, err := recs[k-1].game()
+		if err != nil {
+			return err
+		}
+
+		start, st := gm.brd, gm.st
+		if 0 < len(gm.hst) {
+			start, st = gm.hst[0].psn.brd, gm.hst[0].psn.st
+		}
+
+		r.start(copyBoard(start), st)
+		r.gm = gm
+		return r.show(nil)
+	default:
+		return fmt.Errorf("usage: load agent <file> | load game <file> [number]")
+	}
+}
+
+// save appends the game's record to a file. Its seed is zero, as the game is not
+// reproduced by playing its players again.
+func (r *repl) save(args []string) error {
+	if len(args) != 2 || args[0] != "game" {
+		return fmt.Errorf("usage: save game <file>")
+	}
+
+	if err := writeRecords(args[1], []*record{newRecord(r.gm, 0, "", "")}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(r.out, "saved to %s\n", args[1])
+	return nil
+}
+
+// set changes how boards are shown.
+func (r *repl) set(args []string) error {
+	if len(args) != 2 || args[1] != "on" && args[1] != "off" {
+		return fmt.Errorf("usage: set <coords|unicode|color|flip|compact> <on|off>")
+	}
+
+	on := args[1] == "on"
+	switch args[0] {
+	case "coords":
+		r.term.coords = on
+	case "unicode":
+		r.term.unicode = on
+	case "color":
+		r.term.color = on
+	case "flip":
+		r.term.flip = on
+	case "compact":
+		r.term.compact = on
+	default:
+		return fmt.Errorf("unknown option %q", args[0])
+	}
+
+	return r.show(nil)
+}
+
+// train trains an agent for each side on a number of random games, creating
+// agents for sides that have none.
+func (r *repl) train(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: train <games>")
+	}
+
+	numGames, err := strconv.Atoi(args[0])
+	if err != nil || numGames < 1 {
+		return fmt.Errorf("invalid number of games %q", args[0])
+	}
+
+	m, n := r.gm.dims()
+	for k, sd := range []side{whiteSide, blackSide} {
+		ap := r.agents[sd]
+		if ap == nil {
+			ap = newAutoPlayer(sd, m, n, deriveSeed(r.seed, int64(k)))
+			r.agents[sd] = ap
+		}
+
+		start := time.Now()
+		ap.train(numGames, 0.1)
+		fmt.Fprintf(r.out, "trained the %s agent on %d games in %v; it knows %d positions\n", sideName(sd), numGames, time.Since(start).Round(time.Millisecond), len(ap.psns))
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// replCmd starts an interactive shell for playing and analyzing games. Commands
+// are read from standard input until it ends or quit is entered.
+func replCmd(args []string) error {
+	var (
+		fs   = flag.NewFlagSet("repl", flag.ContinueOnError)
+		m    = fs.Int("m", 3, "number of rows")
+		n    = fs.Int("n", 3, "number of columns")
+		seed = fs.Int64("seed", 1, "seed of agents created by training")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *m < 3 || *n < 3 {
+		return fmt.Errorf("repl: invalid dimensions %dx%d", *m, *n)
+	}
+
+	r := newRepl(*m, *n, *seed, os.Stdout)
+	fmt.Fprintln(r.out, "type help for a list of commands")
+	r.show(nil)
+	r.loop(os.Stdin)
+	return nil
+}
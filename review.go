@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Classes of reviewed moves
+const (
+	blunder    = "blunder"    // Move provably changes the result of the game with perfect play
+	inaccuracy = "inaccuracy" // Move delays a win, or may change the result where search cannot prove it does
+)
+
+// assessment is the value of selecting a pawn option for the side selecting it.
+// A solver proves every result. Search proves only results within its depth and
+// otherwise estimates a score. A result search has not proven is ordered as a
+// stalemate, but is never taken as proof of one.
+type assessment struct {
+	sol    solution // Solution of selecting the pawn option; a stalemate in zero plies if not proven
+	score  int      // Search score of selecting the pawn option; zero if solved
+	proven bool     // Indicates the solution is proven
+}
+
+// String returns a description of an assessment, such as "win in 3" or "+17".
+func (a assessment) String() string {
+	if a.proven {
+		return a.sol.String()
+	}
+
+	return fmt.Sprintf("%+d", a.score)
+}
+
+// reviewer assesses the pawn options selected in games, by solver if it has one
+// and by search otherwise.
+type reviewer struct {
+	slv *solver       // Solver proving results; nil if search is used
+	sp  *searchPlayer // Search estimating scores; nil if the solver is used
+}
+
+// newSolvingReviewer returns a reviewer assessing pawn options by solver.
+func newSolvingReviewer(slv *solver) *reviewer {
+	return &reviewer{slv: slv}
+}
+
+// newSearchingReviewer returns a reviewer assessing pawn options by search of a
+// number of plies.
+func newSearchingReviewer(depth int) *reviewer {
+	return &reviewer{sp: newSearchPlayer(depth)}
+}
+
+// assess returns the assessment of selecting a pawn option at a grid and state.
+func (rvr *reviewer) assess(g grid, st state, po *pawnOpt) assessment {
+	if rvr.slv != nil {
+		return assessment{sol: rvr.slv.solvePawnOpt(g, st, po), proven: true}
+	}
+
+	a := assessment{sol: solution{res: draw}, score: rvr.sp.scorePawnOpt(g, st, po, rvr.sp.depth, -winScore<<1, winScore<<1)}
+	switch {
+	case winScore <= a.score:
+		a.sol, a.proven = solution{res: win, plies: rvr.sp.depth - (a.score - winScore) + 1}, true
+	case a.score <= -winScore:
+		a.sol, a.proven = solution{res: loss, plies: rvr.sp.depth - (-a.score - winScore) + 1}, true
+	}
+
+	return a
+}
+
+// moveReview is the review of a pawn option selected in a game.
+type moveReview struct {
+	ply    int        // Number of the ply the pawn option was selected on, starting at one
+	st     state      // State the pawn option was selected in
+	po     *pawnOpt   // Pawn option selected
+	played assessment // Assessment of the pawn option selected
+	best   *pawnOpt   // Best pawn option available
+	bestA  assessment // Assessment of the best pawn option available
+	class  string     // Blunder, inaccuracy, or empty if neither
+}
+
+// gameReview is the review of each pawn option selected in a game.
+type gameReview struct {
+	m, n     int           // Number of rows and columns
+	moves    []*moveReview // Reviews of each pawn option selected, in order
+	blunders map[side]int  // Number of blunders by each side
+	inaccs   map[side]int  // Number of inaccuracies by each side
+}
+
+// review returns the review of each pawn option selected in a game's history.
+// Events selecting no pawn option are not reviewed.
+func (rvr *reviewer) review(hst []*event) *gameReview {
+	gr := &gameReview{
+		moves:    make([]*moveReview, 0, len(hst)),
+		blunders: make(map[side]int),
+		inaccs:   make(map[side]int),
+	}
+
+	for k, evnt := range hst {
+		if k == 0 {
+			gr.m, gr.n = len(evnt.psn.brd), len(evnt.psn.brd[0])
+		}
+
+		if evnt.poSlc == nil {
+			continue
+		}
+
+		var (
+			psn = evnt.psn
+			g   = newGrid(psn.brd)
+			mr  = &moveReview{ply: k + 1, st: psn.st, po: evnt.poSlc}
+		)
+
+		for i, po := range g.pawnOpts(psn.st) {
+			a := rvr.assess(g, psn.st, po)
+			if i == 0 || betterAssessment(a, mr.bestA) {
+				mr.best, mr.bestA = po, a
+			}
+
+			if po.m == mr.po.m && po.n == mr.po.n && po.act == mr.po.act {
+				mr.played = a
+			}
+		}
+
+		mr.class = classify(mr.played, mr.bestA)
+		switch sd := sideOf(psn.st); mr.class {
+		case blunder:
+			gr.blunders[sd]++
+		case inaccuracy:
+			gr.inaccs[sd]++
+		}
+
+		gr.moves = append(gr.moves, mr)
+	}
+
+	return gr
+}
+
+// betterAssessment returns true if an assessment is preferred over another by
+// the side it is for. Unproven assessments are compared by score.
+func betterAssessment(a, other assessment) bool {
+	if a.sol != other.sol {
+		return a.sol.better(other.sol)
+	}
+
+	return other.score < a.score
+}
+
+// classify returns the class of a pawn option given its assessment and the
+// assessment of the best pawn option available. Only a pawn option proven worse
+// than a proven best is a blunder. A pawn option search cannot resolve may still
+// win beyond the search depth, so passing over a proven win for it delays the
+// win at best, and a proven loss chosen over a pawn option search cannot resolve
+// may be no worse than it.
+func classify(played, best assessment) string {
+	switch {
+	case played.sol.res < best.sol.res && played.proven && best.proven:
+		return blunder
+	case played.sol.res < best.sol.res:
+		return inaccuracy
+	case played.sol.res == win && best.sol.plies < played.sol.plies:
+		return inaccuracy
+	default:
+		return ""
+	}
+}
+
+// comment returns a comment on a reviewed pawn option, such as "blunder: loss in
+// 4; best is b2xc3, win in 3". Pawn options that are neither blunders nor
+// inaccuracies have no comment.
+func (mr *moveReview) comment(m int) string {
+	if mr.class == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s: %v; best is %s, %v", mr.class, mr.played, moveNotation(mr.best, mr.st, m), mr.bestA)
+}
+
+// classGlyph returns the symbol annotating a pawn option of a class: ?? for a
+// blunder and ?! for an inaccuracy.
+func classGlyph(class string) string {
+	switch class {
+	case blunder:
+		return "??"
+	case inaccuracy:
+		return "?!"
+	default:
+		return ""
+	}
+}
+
+// annotate the record of a reviewed game with a comment on each blunder and
+// inaccuracy.
+func (gr *gameReview) annotate(rec *record) {
+	rec.Annotations = rec.Annotations[:0]
+	for _, mr := range gr.moves {
+		if mr.class == "" {
+			continue
+		}
+
+		rec.Annotations = append(rec.Annotations, annotation{
+			Ply:     mr.ply,
+			Class:   mr.class,
+			Comment: mr.comment(gr.m),
+			Best:    moveNotation(mr.best, mr.st, gr.m),
+		})
+	}
+}
+
+// writeReview writes a reviewed game as a line per pawn option selected,
+// followed by the number of blunders and inaccuracies by each side.
+func writeReview(w io.Writer, gr *gameReview) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ply\tside\tmove\tassessment\tcomment\t")
+	for _, mr := range gr.moves {
+		fmt.Fprintf(tw, "%d\t%s\t%s%s\t%v\t%s\t\n", mr.ply, sideName(sideOf(mr.st)), moveNotation(mr.po, mr.st, gr.m), classGlyph(mr.class), mr.played, mr.comment(gr.m))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w, mistakeCounts(gr.blunders, gr.inaccs))
+	return err
+}
+
+// mistakeCounts returns a description of the number of blunders and inaccuracies
+// by each side.
+func mistakeCounts(blunders, inaccs map[side]int) string {
+	return fmt.Sprintf("white: %d blunders, %d inaccuracies; black: %d blunders, %d inaccuracies", blunders[whiteSide], inaccs[whiteSide], blunders[blackSide], inaccs[blackSide])
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// reviewCmd reviews recorded games with a solver or search, listing each move
+// with its assessment and flagging blunders and inaccuracies. Annotated records
+// of the games may be written.
+func reviewCmd(args []string) error {
+	var (
+		fs      = flag.NewFlagSet("review", flag.ContinueOnError)
+		recPath = fs.String("records", "", "file of game records to review")
+		gameNum = fs.Int("game", 0, "number of the recorded game reviewed (0 reviews every game)")
+		depth   = fs.Int("depth", 0, fmt.Sprintf("number of plies searched to assess moves (0 solves boards of at most %d squares and searches %d plies on larger boards)", analysisSolveSquares, analysisDepth))
+		out     = fs.String("out", "", "file the annotated game records are appended to")
+		quiet   = fs.Bool("quiet", false, "list only blunders and inaccuracies")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *recPath == "" {
+		return fmt.Errorf("review: no records file given")
+	}
+
+	if *depth < 0 {
+		return fmt.Errorf("review: invalid depth %d", *depth)
+	}
+
+	recs, err := readRecords(*recPath)
+	if err != nil {
+		return fmt.Errorf("review: %v", err)
+	}
+
+	if *gameNum < 0 || len(recs) < *gameNum {
+		return fmt.Errorf("review: %s has no game %d", *recPath, *gameNum)
+	}
+
+	first, last := 0, len(recs)
+	if 0 < *gameNum {
+		first, last = *gameNum-1, *gameNum
+	}
+
+	var (
+		blunders = make(map[side]int)
+		inaccs   = make(map[side]int)
+		slv      = newSolver()
+	)
+
+	for k := first; k < last; k++ {
+		rec := recs[k]
+		gm, err := rec.game()
+		if err != nil {
+			return fmt.Errorf("review: game %d: %v", k+1, err)
+		}
+
+		rvr := newSolvingReviewer(slv)
+		switch {
+		case 0 < *depth:
+			rvr = newSearchingReviewer(*depth)
+		case analysisSolveSquares < rec.Rows*rec.Columns:
+			rvr = newSearchingReviewer(analysisDepth)
+		}
+
+		gr := rvr.review(gm.hst)
+		for _, sd := range []side{whiteSide, blackSide} {
+			blunders[sd] += gr.blunders[sd]
+			inaccs[sd] += gr.inaccs[sd]
+		}
+
+		if k != first {
+			fmt.Println()
+		}
+
+		fmt.Printf("game %d: %s vs %s, %s\n", k+1, playerName(rec.White, "white"), playerName(rec.Black, "black"), rec.Result)
+		if *quiet {
+			gr.moves = flagged(gr.moves)
+		}
+
+		if err := writeReview(os.Stdout, gr); err != nil {
+			return fmt.Errorf("review: %v", err)
+		}
+
+		if *out != "" {
+			gr.annotate(rec)
+		}
+	}
+
+	if 1 < last-first {
+		fmt.Printf("\ntotal %s\n", mistakeCounts(blunders, inaccs))
+	}
+
+	if *out != "" {
+		if err := writeRecords(*out, recs[first:last]); err != nil {
+			return fmt.Errorf("review: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// playerName returns the name of a recorded player, or a default name if the
+// player is unnamed.
+func playerName(name, dflt string) string {
+	if name == "" {
+		return dflt
+	}
+
+	return name
+}
+
+// flagged returns the reviews of blunders and inaccuracies.
+func flagged(mrs []*moveReview) []*moveReview {
+	fmrs := make([]*moveReview, 0, len(mrs))
+	for _, mr := range mrs {
+		if mr.class != "" {
+			fmrs = append(fmrs, mr)
+		}
+	}
+
+	return fmrs
+}
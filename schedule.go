@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// decay indicates how a scheduled value changes over a training session.
+type decay byte
+
+// Decays
+const (
+	constantDecay    decay = iota // Value never changes
+	linearDecay                   // Value moves linearly from start to end over the session
+	exponentialDecay              // Value is multiplied by a factor each game
+	inverseSqrtDecay              // Value is divided by the square root of the number of periods played
+	stepDecay                     // Value is multiplied by a factor each period
+)
+
+// decayNames maps each decay to the name it is configured and recorded by.
+var decayNames = map[decay]string{
+	constantDecay:    "constant",
+	linearDecay:      "linear",
+	exponentialDecay: "exponential",
+	inverseSqrtDecay: "inverse-sqrt",
+	stepDecay:        "step",
+}
+
+// schedule determines a value, such as the learning rate or exploration
+// temperature, for each game of a training session.
+type schedule struct {
+	dcy    decay   // How the value changes
+	start  float64 // Value of the first game
+	end    float64 // Value of the final game (linear decay only)
+	factor float64 // Multiplier applied each game (exponential) or period (step)
+	period int     // Number of games per period (inverse square root and step decay only)
+}
+
+// String returns a formated representation of a schedule. The representation
+// can be parsed by parseSchedule.
+func (sch schedule) String() string {
+	switch sch.dcy {
+	case constantDecay:
+		return fmt.Sprintf("constant:%g", sch.start)
+	case linearDecay:
+		return fmt.Sprintf("linear:%g:%g", sch.start, sch.end)
+	case exponentialDecay:
+		return fmt.Sprintf("exponential:%g:%g", sch.start, sch.factor)
+	case inverseSqrtDecay:
+		return fmt.Sprintf("inverse-sqrt:%g:%d", sch.start, sch.period)
+	case stepDecay:
+		return fmt.Sprintf("step:%g:%g:%d", sch.start, sch.factor, sch.period)
+	default:
+		return "unknown schedule"
+	}
+}
+
+// constantSchedule returns a schedule that never changes.
+func constantSchedule(x float64) schedule {
+	return schedule{dcy: constantDecay, start: x}
+}
+
+// value returns the scheduled value of the kth game of a session of numGames
+// games.
+func (sch schedule) value(k, numGames int) float64 {
+	switch sch.dcy {
+	case constantDecay:
+		return sch.start
+	case linearDecay:
+		if numGames < 2 {
+			return sch.start
+		}
+
+		return sch.start + (sch.end-sch.start)*float64(k)/float64(numGames-1)
+	case exponentialDecay:
+		return sch.start * math.Pow(sch.factor, float64(k))
+	case inverseSqrtDecay:
+		return sch.start / math.Sqrt(1+float64(k/sch.period))
+	case stepDecay:
+		return sch.start * math.Pow(sch.factor, float64(k/sch.period))
+	default:
+		panic("value: unknown decay")
+	}
+}
+
+// validate returns an error if a schedule cannot produce a value for every game.
+func (sch schedule) validate() error {
+	if _, ok := decayNames[sch.dcy]; !ok {
+		return fmt.Errorf("unknown decay %d", sch.dcy)
+	}
+
+	if (sch.dcy == inverseSqrtDecay || sch.dcy == stepDecay) && sch.period < 1 {
+		return fmt.Errorf("%s decay requires a positive period", decayNames[sch.dcy])
+	}
+
+	return nil
+}
+
+// parseDecay returns the decay with a given name.
+func parseDecay(s string) (decay, error) {
+	for dcy, name := range decayNames {
+		if name == s {
+			return dcy, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown decay %q", s)
+}
+
+// parseSchedule returns the schedule represented by a string of the form
+// decay:start[:parameters], where the parameters are the end value for linear
+// decay, the factor for exponential decay, the period for inverse square root
+// decay, and the factor and period for step decay. A number alone is a constant
+// schedule.
+func parseSchedule(s string) (schedule, error) {
+	fields := strings.Split(s, ":")
+	if len(fields) == 1 {
+		fields = []string{decayNames[constantDecay], fields[0]}
+	}
+
+	dcy, err := parseDecay(fields[0])
+	if err != nil {
+		return schedule{}, err
+	}
+
+	params := map[decay]int{
+		constantDecay:    1,
+		linearDecay:      2,
+		exponentialDecay: 2,
+		inverseSqrtDecay: 2,
+		stepDecay:        3,
+	}
+
+	if len(fields)-1 != params[dcy] {
+		return schedule{}, fmt.Errorf("%s schedule requires %d parameters, got %d", fields[0], params[dcy], len(fields)-1)
+	}
+
+	sch := schedule{dcy: dcy}
+	if sch.start, err = strconv.ParseFloat(fields[1], 64); err != nil {
+		return schedule{}, fmt.Errorf("invalid start %q", fields[1])
+	}
+
+	switch dcy {
+	case linearDecay:
+		sch.end, err = strconv.ParseFloat(fields[2], 64)
+	case exponentialDecay:
+		sch.factor, err = strconv.ParseFloat(fields[2], 64)
+	case inverseSqrtDecay:
+		sch.period, err = strconv.Atoi(fields[2])
+	case stepDecay:
+		if sch.factor, err = strconv.ParseFloat(fields[2], 64); err == nil {
+			sch.period, err = strconv.Atoi(fields[3])
+		}
+	}
+
+	if err != nil {
+		return schedule{}, fmt.Errorf("invalid %s schedule %q", fields[0], s)
+	}
+
+	if err := sch.validate(); err != nil {
+		return schedule{}, err
+	}
+
+	return sch, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import "math/bits"
+
+// Search scores are relative to the side to move. A won position scores more than
+// any heuristic evaluation, and sooner wins score more than later ones.
+const (
+	winScore  = 1 << 20 // Score of a won position before adjusting for depth
+	pawnScore = 16      // Score of a pawn before adjusting for advancement
+)
+
+// searchPlayer selects pawn options by a depth-limited negamax search with
+// alpha-beta pruning. Positions beyond the search depth are scored by material
+// and pawn advancement.
+type searchPlayer struct {
+	depth int // Number of plies to search
+}
+
+// newSearchPlayer returns a search player that searches a number of plies.
+func newSearchPlayer(depth int) *searchPlayer {
+	if depth < 1 {
+		panic("newSearchPlayer: depth must be positive")
+	}
+
+	return &searchPlayer{depth: depth}
+}
+
+// clone returns a search player. Search players have no state, so the copy is
+// the search player itself.
+func (sp *searchPlayer) clone() player {
+	return sp
+}
+
+// chooseEvent returns an event selecting the highest scoring pawn option at a
+// position. Ties are broken by the order of the pawn options. An event with no
+// pawn option selected is returned if a position has no available pawn options.
+func (sp *searchPlayer) chooseEvent(psn *position) *event {
+	var (
+		best      *pawnOpt // Highest scoring pawn option
+		bestScore = -winScore << 1
+	)
+
+	g := newGrid(psn.brd)
+	for _, po := range psn.pos {
+		if score := sp.scorePawnOpt(g, psn.st, po, sp.depth, bestScore, winScore<<1); bestScore < score {
+			best, bestScore = po, score
+		}
+	}
+
+	if best == nil {
+		return &event{psn: copyPosition(psn)}
+	}
+
+	return &event{psn: copyPosition(psn), poSlc: copyPawnOpt(best)}
+}
+
+// scorePawnOpt returns the score of selecting a pawn option relative to the side
+// selecting it.
+func (sp *searchPlayer) scorePawnOpt(g grid, st state, po *pawnOpt, depth, alpha, beta int) int {
+	child, childSt := g.apply(po, st)
+	switch childSt {
+	case whiteWin, blackWin:
+		return winScore + depth
+	default:
+		return -sp.negamax(child, childSt, depth-1, -beta, -alpha)
+	}
+}
+
+// negamax returns the score of a grid relative to the side to move.
+func (sp *searchPlayer) negamax(g grid, st state, depth, alpha, beta int) int {
+	pos := g.pawnOpts(st)
+	if len(pos) == 0 {
+		return 0 // Stalemate
+	}
+
+	if depth == 0 {
+		return evaluate(g, st)
+	}
+
+	for _, po := range pos {
+		if score := sp.scorePawnOpt(g, st, po, depth, alpha, beta); alpha < score {
+			alpha = score
+			if beta <= alpha {
+				break
+			}
+		}
+	}
+
+	return alpha
+}
+
+// evaluate returns a heuristic score of a grid relative to the side to move.
+// Each pawn is worth a constant plus the number of rows it has advanced.
+func evaluate(g grid, st state) int {
+	var score int
+	switch g := g.(type) {
+	case bitboard:
+		for i := 0; i < g.m; i++ {
+			row := g.row(i)
+			score += (pawnScore + g.m - 1 - i) * bits.OnesCount64(g.white&row)
+			score -= (pawnScore + i) * bits.OnesCount64(g.black&row)
+		}
+	default:
+		brd := g.toBoard()
+		for i := range brd {
+			for _, p := range brd[i] {
+				switch p {
+				case whitePawn:
+					score += pawnScore + len(brd) - 1 - i
+				case blackPawn:
+					score -= pawnScore + i
+				}
+			}
+		}
+	}
+
+	if st == blackTurn {
+		return -score
+	}
+
+	return score
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// seeder is a player whose random source can be reseeded. Reseeding every player
+// in a game with the same seeds reproduces the game exactly.
+type seeder interface {
+	reseed(seed int64)
+}
+
+// newRand returns a random source seeded with a seed. Random sources are not
+// safe for concurrent use, so each player and training run has its own.
+func newRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// clockSeed returns a seed taken from the clock for when no seed is given.
+func clockSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// deriveSeed returns a seed derived from a seed and a sequence of integers, such
+// as a game or worker number, so independent random sources can be created from
+// a single seed.
+func deriveSeed(seed int64, xs ...int64) int64 {
+	h := splitmix64(uint64(seed))
+	for _, x := range xs {
+		h = splitmix64(h ^ splitmix64(uint64(x)))
+	}
+
+	return int64(h)
+}
+
+// reseedPlayer reseeds a player if it has a random source.
+func reseedPlayer(p player, seed int64) {
+	if s, ok := p.(seeder); ok {
+		s.reseed(seed)
+	}
+}
+
+// playSeeded plays a game between two players on an m-by-n board after reseeding
+// each player from a seed, so the same players and seed always play the same
+// game.
+func playSeeded(white, black player, m, n int, seed int64) *game {
+	reseedPlayer(white, deriveSeed(seed, 0))
+	reseedPlayer(black, deriveSeed(seed, 1))
+	return playGame(white, black, m, n)
+}
+
+// splitmix64 returns a well mixed hash of an integer.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestReproducible checks that training with a seed, serially or in parallel,
+// always produces the same auto player, and that the auto players trained play
+// the same games when seeded equally.
+func TestReproducible(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		cfg := trainConfig{
+			numGames:     300,
+			seed:         7,
+			workers:      workers,
+			learningRate: constantSchedule(0.1),
+			temperature:  constantSchedule(1),
+			credit:       defaultCreditConfig(),
+		}
+
+		var (
+			aps = make([]*autoPlayer, 0, 2)
+			gms = make([]*game, 0, 2)
+		)
+
+		for k := 0; k < 2; k++ {
+			ap := newAutoPlayer(whiteSide, 3, 4, 1)
+			ap.trainAgainst(newRandomPlayer(0), cfg)
+			aps = append(aps, ap)
+			gms = append(gms, playSeeded(fixedPlayer(ap), newRandomPlayer(0), 3, 4, 8))
+		}
+
+		if !equalAgents(aps[0], aps[1]) {
+			t.Errorf("%d workers: training with equal seeds produced different auto players", workers)
+		}
+
+		a, b := strings.Join(newRecord(gms[0], 0, "", "").Moves, " "), strings.Join(newRecord(gms[1], 0, "", "").Moves, " ")
+		if a != b {
+			t.Errorf("%d workers: games with equal seeds differ: %s and %s", workers, a, b)
+		}
+	}
+}
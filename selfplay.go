@@ -0,0 +1,89 @@
+package main
+
+// selfPlayConfig determines how a pair of auto players are trained against each
+// other.
+type selfPlayConfig struct {
+	trainConfig           // Determines how each side is trained
+	poolSize      int     // Maximum number of past snapshots kept per side; zero disables the pool
+	snapshotEvery int     // Number of games played between snapshots
+	poolRate      float64 // Probability of playing against a past snapshot instead of the current opponent
+}
+
+// selfPlay trains a white and a black auto player on games played against each
+// other. If white and black are the same auto player, it learns from the moves of
+// both sides. When an opponent pool is configured, snapshots of past versions of
+// each side are kept and played against from time to time, without altering
+// them, so that neither side only learns to beat the current version of the
+// other.
+func selfPlay(white, black *autoPlayer, cfg selfPlayConfig) {
+	if white.m != black.m || white.n != black.n {
+		panic("selfPlay: auto players must play on boards of equal dimensions")
+	}
+
+	if white != black && (white.sd != whiteSide || black.sd != blackSide) {
+		panic("selfPlay: auto players must be assigned to white and black respectively")
+	}
+
+	if 0 < cfg.poolSize && cfg.snapshotEvery < 1 {
+		panic("selfPlay: snapshots must be taken at least every game")
+	}
+
+	cfg.mustValidate()
+
+	var (
+		whitePool = make([]*autoPlayer, 0, cfg.poolSize) // Past snapshots of white
+		blackPool = make([]*autoPlayer, 0, cfg.poolSize) // Past snapshots of black
+		gm        *game                                  // Game played for each training session
+		lr        weight                                 // Learning rate of each game
+		whiteTemp = white.temp                           // Temperature of white before training
+		blackTemp = black.temp                           // Temperature of black before training
+		rnd       = newRand(cfg.seed)                    // Source of opponent selections
+	)
+
+	white.reseed(deriveSeed(cfg.seed, 0))
+	black.reseed(deriveSeed(cfg.seed, 1))
+
+	for k := 0; k < cfg.numGames; k++ {
+		if 0 < cfg.poolSize && 0 < k && k%cfg.snapshotEvery == 0 {
+			whitePool = pushSnapshot(whitePool, copyAutoPlayer(white), cfg.poolSize)
+			if white == black {
+				blackPool = whitePool
+			} else {
+				blackPool = pushSnapshot(blackPool, copyAutoPlayer(black), cfg.poolSize)
+			}
+		}
+
+		white.temp = cfg.temperature.value(k, cfg.numGames)
+		black.temp = white.temp
+		lr = weight(cfg.learningRate.value(k, cfg.numGames))
+
+		switch {
+		case len(whitePool) == 0 || cfg.poolRate <= rnd.Float64():
+			gm = playGame(white, black, white.m, white.n)
+			white.learn(gm.hst, gm.st, whiteSide, lr, cfg.credit)
+			black.learn(gm.hst, gm.st, blackSide, lr, cfg.credit)
+		case rnd.Intn(2) == 0:
+			gm = playGame(white, fixedPlayer(randAutoPlayer(blackPool, rnd)), white.m, white.n)
+			white.learn(gm.hst, gm.st, whiteSide, lr, cfg.credit)
+		default:
+			gm = playGame(fixedPlayer(randAutoPlayer(whitePool, rnd)), black, white.m, white.n)
+			black.learn(gm.hst, gm.st, blackSide, lr, cfg.credit)
+		}
+	}
+
+	white.temp, black.temp = whiteTemp, blackTemp
+	white.sessions = append(white.sessions, cfg.trainConfig)
+	if white != black {
+		black.sessions = append(black.sessions, cfg.trainConfig)
+	}
+}
+
+// pushSnapshot appends a snapshot to a pool, dropping the oldest snapshot if the
+// pool is full.
+func pushSnapshot(pool []*autoPlayer, ap *autoPlayer, poolSize int) []*autoPlayer {
+	if len(pool) == poolSize {
+		pool = append(pool[:0], pool[1:]...)
+	}
+
+	return append(pool, ap)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSelfPlay checks that a white and a black auto player trained by self-play,
+// with and without an opponent pool, both learn from the games they share.
+func TestSelfPlay(t *testing.T) {
+	for _, poolSize := range []int{0, 3} {
+		white, black := newAutoPlayer(whiteSide, 3, 3, 1), newAutoPlayer(blackSide, 3, 3, 2)
+		selfPlay(white, black, selfPlayConfig{
+			trainConfig: trainConfig{
+				numGames:     500,
+				seed:         3,
+				learningRate: constantSchedule(0.1),
+				temperature:  constantSchedule(1),
+				credit:       defaultCreditConfig(),
+			},
+			poolSize:      poolSize,
+			snapshotEvery: 100,
+			poolRate:      0.5,
+		})
+
+		for _, ap := range []*autoPlayer{white, black} {
+			if !trained(ap) {
+				t.Errorf("pool of %d: %s weights are unchanged by self-play", poolSize, sideName(ap.sd))
+			}
+		}
+	}
+}
+
+// trained returns true if an auto player weights any pawn option other than
+// evenly among the pawn options of its position.
+func trained(ap *autoPlayer) bool {
+	for _, psn := range ap.psns {
+		for _, po := range psn.pos {
+			if 1e-9 < math.Abs(float64(po.wght)-1/float64(len(psn.pos))) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// solveCmd solves the start of a game on each board size in a range and writes a
+// table of the outcomes with perfect play.
+func solveCmd(args []string) error {
+	var (
+		fs     = flag.NewFlagSet("solve", flag.ContinueOnError)
+		minM   = fs.Int("min-m", 3, "least number of rows")
+		maxM   = fs.Int("max-m", 5, "greatest number of rows")
+		minN   = fs.Int("min-n", 3, "least number of columns")
+		maxN   = fs.Int("max-n", 5, "greatest number of columns")
+		format = fs.String("format", "text", "output format (text or csv)")
+		out    = fs.String("out", "", "file the table is written to (default standard output)")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *minM < 3 || *minN < 3 || *maxM < *minM || *maxN < *minN {
+		return fmt.Errorf("solve: invalid range %dx%d to %dx%d", *minM, *minN, *maxM, *maxN)
+	}
+
+	var write func(w io.Writer, sss []sizeSolution) error
+	switch *format {
+	case "text":
+		write = writeSolutionsText
+	case "csv":
+		write = writeSolutionsCSV
+	default:
+		return fmt.Errorf("solve: unknown format %q", *format)
+	}
+
+	sss := make([]sizeSolution, 0, (*maxM-*minM+1)*(*maxN-*minN+1))
+	for i := *minM; i <= *maxM; i++ {
+		for j := *minN; j <= *maxN; j++ {
+			sss = append(sss, solveSize(i, j))
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("solve: %v", err)
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	if err := write(w, sss); err != nil {
+		return fmt.Errorf("solve: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import "fmt"
+
+// result is the game-theoretic result of a position for the side to move.
+type result byte
+
+// Results
+const (
+	loss result = iota // Side to move loses with perfect play from both sides
+	draw               // Stalemate is reached with perfect play from both sides
+	win                // Side to move wins with perfect play from both sides
+)
+
+// solution is the result of a position and the number of plies remaining with
+// perfect play. The winning side ends the game as soon as it can and the losing
+// side delays the end as long as it can.
+type solution struct {
+	res   result // Result for the side to move
+	plies int    // Number of plies until the game ends
+}
+
+// better returns true if a solution is preferred over another by the side it is
+// the result for.
+func (sol solution) better(other solution) bool {
+	switch {
+	case sol.res != other.res:
+		return other.res < sol.res
+	case sol.res == loss:
+		return other.plies < sol.plies
+	default:
+		return sol.plies < other.plies
+	}
+}
+
+// invert returns the solution for the opponent of the side to move a ply
+// earlier.
+func (sol solution) invert() solution {
+	return solution{res: win - sol.res, plies: sol.plies + 1}
+}
+
+// String returns a description of a solution, such as "win in 5".
+func (sol solution) String() string {
+	switch sol.res {
+	case win:
+		return fmt.Sprintf("win in %d", sol.plies)
+	case loss:
+		return fmt.Sprintf("loss in %d", sol.plies)
+	default:
+		return fmt.Sprintf("stalemate in %d", sol.plies)
+	}
+}
+
+// solver determines the game-theoretic result of positions by exhaustive
+// search. Solved positions are remembered, so each position is searched once.
+type solver struct {
+	memo map[uint64]solution // Solutions of positions solved so far, by zobrist hash
+}
+
+// newSolver returns a solver that has solved no positions.
+func newSolver() *solver {
+	return &solver{memo: make(map[uint64]solution)}
+}
+
+// solve returns the solution of a grid and state. The state must be either white
+// or black turn. Positions are remembered by their zobrist hash, so a solver may
+// be given grids of any representation.
+func (slv *solver) solve(g grid, st state) solution {
+	key := g.zobrist(st)
+	if sol, ok := slv.memo[key]; ok {
+		return sol
+	}
+
+	pos := g.pawnOpts(st)
+	if len(pos) == 0 {
+		slv.memo[key] = solution{res: draw} // Stalemate
+		return slv.memo[key]
+	}
+
+	var best solution
+	for i, po := range pos {
+		if sol := slv.solvePawnOpt(g, st, po); i == 0 || sol.better(best) {
+			best = sol
+		}
+	}
+
+	slv.memo[key] = best
+	return best
+}
+
+// solvePawnOpt returns the solution of selecting a pawn option at a grid and
+// state for the side selecting it.
+func (slv *solver) solvePawnOpt(g grid, st state, po *pawnOpt) solution {
+	child, childSt := g.apply(po, st)
+	switch childSt {
+	case whiteWin, blackWin:
+		return solution{res: win, plies: 1}
+	default:
+		return slv.solve(child, childSt).invert()
+	}
+}
+
+// solverPlayer selects pawn options that are optimal with perfect play.
+type solverPlayer struct {
+	slv *solver
+}
+
+// newSolverPlayer returns a solver player.
+func newSolverPlayer() *solverPlayer {
+	return &solverPlayer{slv: newSolver()}
+}
+
+// clone returns a solver player with its own solver, as solvers are not safe for
+// concurrent use.
+func (sp *solverPlayer) clone() player {
+	return newSolverPlayer()
+}
+
+// chooseEvent returns an event selecting the first optimal pawn option at a
+// position. An event with no pawn option selected is returned if a position has
+// no available pawn options.
+func (sp *solverPlayer) chooseEvent(psn *position) *event {
+	var (
+		best    *pawnOpt // First optimal pawn option
+		bestSol solution // Solution of selecting the best pawn option
+	)
+
+	g := newGrid(psn.brd)
+	for _, po := range psn.pos {
+		if sol := sp.slv.solvePawnOpt(g, psn.st, po); best == nil || sol.better(bestSol) {
+			best, bestSol = po, sol
+		}
+	}
+
+	if best == nil {
+		return &event{psn: copyPosition(psn)}
+	}
+
+	return &event{psn: copyPosition(psn), poSlc: copyPawnOpt(best)}
+}
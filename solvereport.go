@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// sizeSolution is the game-theoretic result of the start of a game on an m-by-n
+// board.
+type sizeSolution struct {
+	m         int           // Number of rows
+	n         int           // Number of columns
+	sol       solution      // Solution for white, who moves first
+	best      *pawnOpt      // First optimal pawn option for white
+	wins      pawnOpts      // Pawn options white wins by selecting
+	positions int           // Number of positions solved
+	dur       time.Duration // Time taken to solve
+}
+
+// solveSize returns the solution of the start of a game on an m-by-n board.
+func solveSize(m, n int) sizeSolution {
+	ss := sizeSolution{m: m, n: n}
+	start := time.Now()
+
+	slv := newSolver()
+	g := newGrid(newBoard(m, n))
+	for _, po := range g.pawnOpts(whiteTurn) {
+		sol := slv.solvePawnOpt(g, whiteTurn, po)
+		if ss.best == nil || sol.better(ss.sol) {
+			ss.best, ss.sol = po, sol
+		}
+
+		if sol.res == win {
+			ss.wins = append(ss.wins, po)
+		}
+	}
+
+	ss.positions = len(slv.memo)
+	ss.dur = time.Since(start)
+	return ss
+}
+
+// outcome returns the outcome of a game on the solved board with perfect play
+// from both sides.
+func (ss *sizeSolution) outcome() string {
+	switch ss.sol.res {
+	case win:
+		return "white wins"
+	case loss:
+		return "black wins"
+	default:
+		return "stalemate"
+	}
+}
+
+// bestMove returns the notation of the first optimal move for white.
+func (ss *sizeSolution) bestMove() string {
+	return moveNotation(ss.best, whiteTurn, ss.m)
+}
+
+// winningMoves returns the notation of each move white wins by making, separated
+// by spaces.
+func (ss *sizeSolution) winningMoves() string {
+	mvs := make([]string, 0, len(ss.wins))
+	for _, po := range ss.wins {
+		mvs = append(mvs, moveNotation(po, whiteTurn, ss.m))
+	}
+
+	return strings.Join(mvs, " ")
+}
+
+// writeSolutionsText writes a table of size solutions.
+func writeSolutionsText(w io.Writer, sss []sizeSolution) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "board\toutcome\tplies\tbest move\twinning moves\tpositions\ttime\t")
+	for _, ss := range sss {
+		wins := ss.winningMoves()
+		if wins == "" {
+			wins = "-"
+		}
+
+		fmt.Fprintf(tw, "%dx%d\t%s\t%d\t%s\t%s\t%d\t%v\t\n", ss.m, ss.n, ss.outcome(), ss.sol.plies, ss.bestMove(), wins, ss.positions, ss.dur.Round(time.Millisecond))
+	}
+
+	return tw.Flush()
+}
+
+// writeSolutionsCSV writes size solutions as comma separated values with a
+// header. Times are in seconds.
+func writeSolutionsCSV(w io.Writer, sss []sizeSolution) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"rows", "columns", "outcome", "plies", "best_move", "winning_moves", "positions", "seconds"})
+	for _, ss := range sss {
+		cw.Write([]string{
+			strconv.Itoa(ss.m),
+			strconv.Itoa(ss.n),
+			ss.outcome(),
+			strconv.Itoa(ss.sol.plies),
+			ss.bestMove(),
+			ss.winningMoves(),
+			strconv.Itoa(ss.positions),
+			strconv.FormatFloat(ss.dur.Seconds(), 'f', 6, 64),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// spaceCmd enumerates the positions reachable from the start of a game and
+// reports their statistics. Given -max-m and -max-n, a row of statistics is
+// reported for each board size up to them instead.
+func spaceCmd(args []string) error {
+	var (
+		fs   = flag.NewFlagSet("space", flag.ContinueOnError)
+		m    = fs.Int("m", 3, "number of rows")
+		n    = fs.Int("n", 3, "number of columns")
+		maxM = fs.Int("max-m", 0, "greatest number of rows of the board sizes tabulated")
+		maxN = fs.Int("max-n", 0, "greatest number of columns of the board sizes tabulated")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *maxM != 0 || *maxN != 0 {
+		if *maxM < 3 || *maxN < 3 {
+			return fmt.Errorf("space: invalid dimensions %dx%d", *maxM, *maxN)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "board\tpositions\twhite to move\tblack to move\twhite wins\tblack wins\tstalemates\tavg branching\tmax branching\tlongest\tgames\ttime\t")
+		for i := 3; i <= *maxM; i++ {
+			for j := 3; j <= *maxN; j++ {
+				var ss spaceStats
+				t := timeIt(func() { ss = enumerate(i, j) })
+				fmt.Fprintf(tw, "%dx%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.2f\t%d\t%d\t%s\t%v\t\n", i, j, ss.positions, ss.whiteToMove, ss.blackToMove, ss.whiteWins, ss.blackWins, ss.stalemates, ss.avgBranching(), ss.maxBranching, ss.longest, ss.gamesString(), t.Round(time.Millisecond))
+			}
+		}
+
+		tw.Flush()
+		return nil
+	}
+
+	if *m < 3 || *n < 3 {
+		return fmt.Errorf("space: invalid dimensions %dx%d", *m, *n)
+	}
+
+	var ss spaceStats
+	t := timeIt(func() { ss = enumerate(*m, *n) })
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ply\tpositions\twhite to move\tblack to move\twhite wins\tblack wins\tstalemates\t")
+	for k, ps := range ss.plies {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t\n", k, ps.positions, ps.whiteToMove, ps.blackToMove, ps.whiteWins, ps.blackWins, ps.stalemates)
+	}
+
+	tw.Flush()
+	fmt.Printf("\nboard:          %dx%d\n", ss.m, ss.n)
+	fmt.Printf("positions:      %d\n", ss.positions)
+	fmt.Printf("white to move:  %d\n", ss.whiteToMove)
+	fmt.Printf("black to move:  %d\n", ss.blackToMove)
+	fmt.Printf("white wins:     %d\n", ss.whiteWins)
+	fmt.Printf("black wins:     %d\n", ss.blackWins)
+	fmt.Printf("stalemates:     %d\n", ss.stalemates)
+	fmt.Printf("avg branching:  %.2f\n", ss.avgBranching())
+	fmt.Printf("max branching:  %d\n", ss.maxBranching)
+	fmt.Printf("longest game:   %d plies (bound %d)\n", ss.longest, maxPlies(ss.m, ss.n))
+	fmt.Printf("games:          %s\n", ss.gamesString())
+	fmt.Printf("time:           %v\n", t.Round(time.Millisecond))
+	return nil
+}
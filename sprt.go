@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// A sequential probability ratio test plays games between two players until the
+// games played are enough to accept one of two hypotheses about the Elo
+// difference of the first player over the second: H0, that it is elo0, or H1,
+// that it is elo1. After each game, the log likelihood ratio (LLR) of H1 over
+// H0 is compared with bounds determined by the rates of falsely accepting H1
+// (alpha) and falsely accepting H0 (beta). The LLR is approximated from the mean
+// and variance of the scores of the games, so stalemates count as half a point.
+
+// Verdicts
+const (
+	acceptH0     = "H0 accepted"
+	acceptH1     = "H1 accepted"
+	inconclusive = "inconclusive"
+)
+
+// minScoreVariance is the least variance of the scores of the games assumed in
+// approximating the LLR, about that of one game in a hundred differing from the
+// rest by a whole point. Without it, games that all score the same, such as
+// between two players that always reach stalemate, would leave the LLR at zero
+// and the test could never conclude.
+const minScoreVariance = 0.01
+
+// sprtConfig determines how a sequential probability ratio test is run.
+type sprtConfig struct {
+	m        int     // Number of rows
+	n        int     // Number of columns
+	elo0     float64 // Elo difference of H0
+	elo1     float64 // Elo difference of H1
+	alpha    float64 // Probability of accepting H1 when H0 is true
+	beta     float64 // Probability of accepting H0 when H1 is true
+	maxGames int     // Number of games after which the test is inconclusive
+	seed     int64   // Seed of each game
+}
+
+// sprtResult is the outcome of a sequential probability ratio test.
+type sprtResult struct {
+	tly     tally     // Outcomes from the first player's perspective
+	llrs    []float64 // LLR after each game
+	lower   float64   // LLR at or below which H0 is accepted
+	upper   float64   // LLR at or above which H1 is accepted
+	verdict string    // Hypothesis accepted, if any
+	recs    []*record // Record of each game
+}
+
+// validate returns an error if a config cannot be tested.
+func (cfg sprtConfig) validate() error {
+	switch {
+	case cfg.m < 3 || cfg.n < 3:
+		return fmt.Errorf("invalid dimensions %dx%d", cfg.m, cfg.n)
+	case cfg.elo1 <= cfg.elo0:
+		return fmt.Errorf("elo1 (%g) must exceed elo0 (%g)", cfg.elo1, cfg.elo0)
+	case cfg.alpha <= 0 || 1 <= cfg.alpha || cfg.beta <= 0 || 1 <= cfg.beta:
+		return fmt.Errorf("alpha and beta must be between zero and one")
+	case cfg.maxGames < 1:
+		return fmt.Errorf("invalid number of games %d", cfg.maxGames)
+	default:
+		return nil
+	}
+}
+
+// eloScore returns the expected score of a player an Elo difference stronger
+// than its opponent.
+func eloScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// llr returns the approximate log likelihood ratio of H1 over H0 given the
+// outcomes of the games played so far. It is zero until a game is played.
+func (cfg sprtConfig) llr(tly tally) float64 {
+	n := float64(tly.games())
+	if n == 0 {
+		return 0
+	}
+
+	var (
+		mean     = tly.score()
+		variance = (float64(tly.wins)*(1-mean)*(1-mean) + float64(tly.stalemates)*(0.5-mean)*(0.5-mean) + float64(tly.losses)*mean*mean) / n
+		s0, s1   = eloScore(cfg.elo0), eloScore(cfg.elo1)
+	)
+
+	if variance < minScoreVariance {
+		variance = minScoreVariance
+	}
+
+	return n * (s1 - s0) * (2*mean - s0 - s1) / (2 * variance)
+}
+
+// runSPRT plays games between two entrants, alternating colors, until a
+// hypothesis is accepted or the maximum number of games is played.
+func runSPRT(a, b *entrant, cfg sprtConfig) (*sprtResult, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("runSPRT: %v", err)
+	}
+
+	res := &sprtResult{
+		lower:   math.Log(cfg.beta / (1 - cfg.alpha)),
+		upper:   math.Log((1 - cfg.beta) / cfg.alpha),
+		verdict: inconclusive,
+	}
+
+	for k := 0; k < cfg.maxGames; k++ {
+		var (
+			seed = deriveSeed(cfg.seed, int64(k))
+			gm   *game
+		)
+
+		if k%2 == 0 {
+			gm = playSeeded(a.white, b.black, cfg.m, cfg.n, seed)
+			res.tly.add(gm.st, whiteSide)
+			res.recs = append(res.recs, newRecord(gm, seed, a.name, b.name))
+		} else {
+			gm = playSeeded(b.white, a.black, cfg.m, cfg.n, seed)
+			res.tly.add(gm.st, blackSide)
+			res.recs = append(res.recs, newRecord(gm, seed, b.name, a.name))
+		}
+
+		llr := cfg.llr(res.tly)
+		res.llrs = append(res.llrs, llr)
+		switch {
+		case res.upper <= llr:
+			res.verdict = acceptH1
+			return res, nil
+		case llr <= res.lower:
+			res.verdict = acceptH0
+			return res, nil
+		}
+	}
+
+	return res, nil
+}
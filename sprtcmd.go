@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// sprtCmd runs a sequential probability ratio test between two entrants and
+// reports the LLR trajectory and the verdict.
+func sprtCmd(args []string) error {
+	var (
+		fs       = flag.NewFlagSet("sprt", flag.ContinueOnError)
+		m        = fs.Int("m", 3, "number of rows")
+		n        = fs.Int("n", 3, "number of columns")
+		elo0     = fs.Float64("elo0", 0, "Elo difference of the null hypothesis")
+		elo1     = fs.Float64("elo1", 50, "Elo difference of the alternative hypothesis")
+		alpha    = fs.Float64("alpha", 0.05, "probability of accepting H1 when H0 is true")
+		beta     = fs.Float64("beta", 0.05, "probability of accepting H0 when H1 is true")
+		maxGames = fs.Int("max-games", 10000, "number of games after which the test is inconclusive")
+		every    = fs.Int("every", 100, "number of games between each LLR reported")
+		seed     = fs.Int64("seed", 0, "random seed (0 seeds from the clock)")
+		out      = fs.String("out", "", "file the game records are appended to")
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: hexapawn sprt [flags] entrant entrant")
+		fmt.Fprintln(fs.Output(), "an entrant is [name=]players, where players is a player for both sides or white,black")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("sprt: expected two entrants, got %d", fs.NArg())
+	}
+
+	if *every < 1 {
+		return fmt.Errorf("sprt: invalid reporting interval %d", *every)
+	}
+
+	if *seed == 0 {
+		*seed = clockSeed()
+	}
+
+	a, err := parseEntrant(fs.Arg(0), *m, *n, *seed)
+	if err != nil {
+		return fmt.Errorf("sprt: %v", err)
+	}
+
+	b, err := parseEntrant(fs.Arg(1), *m, *n, *seed)
+	if err != nil {
+		return fmt.Errorf("sprt: %v", err)
+	}
+
+	cfg := sprtConfig{m: *m, n: *n, elo0: *elo0, elo1: *elo1, alpha: *alpha, beta: *beta, maxGames: *maxGames, seed: *seed}
+	res, err := runSPRT(a, b, cfg)
+	if err != nil {
+		return fmt.Errorf("sprt: %v", err)
+	}
+
+	if *out != "" {
+		if err := writeRecords(*out, res.recs); err != nil {
+			return fmt.Errorf("sprt: %v", err)
+		}
+	}
+
+	fmt.Printf("H0: %s - %s = %g Elo, H1: %g Elo, alpha %g, beta %g, seed %d\n", a.name, b.name, cfg.elo0, cfg.elo1, cfg.alpha, cfg.beta, cfg.seed)
+	fmt.Printf("bounds: [%.3f, %.3f]\n\n", res.lower, res.upper)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "games\tLLR\t")
+	for k, llr := range res.llrs {
+		if (k+1)%*every == 0 || k == len(res.llrs)-1 {
+			fmt.Fprintf(tw, "%d\t%.3f\t\n", k+1, llr)
+		}
+	}
+
+	tw.Flush()
+	fmt.Printf("\n%s: %s\nverdict: %s\n", a.name, res.tly, res.verdict)
+	return nil
+}
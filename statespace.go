@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// spaceStats describes the positions reachable from the start of a game on an
+// m-by-n board. A position is a board and the state of the game at it.
+// Positions where the side to move has no pawn options are stalemates; like a
+// game's history, a stalemate takes a ply.
+type spaceStats struct {
+	m            int        // Number of rows
+	n            int        // Number of columns
+	plies        []plyStats // Positions reachable in each number of plies
+	positions    int        // Number of distinct positions
+	whiteToMove  int        // Number of positions with white to move, including stalemates
+	blackToMove  int        // Number of positions with black to move, including stalemates
+	whiteWins    int        // Number of positions won by white
+	blackWins    int        // Number of positions won by black
+	stalemates   int        // Number of positions the side to move has no pawn options at
+	pawnOpts     int        // Number of pawn options over all positions
+	maxBranching int        // Greatest number of pawn options at a position
+	longest      int        // Number of plies in the longest game
+	games        uint64     // Number of distinct games; saturates at the largest uint64
+}
+
+// plyStats describes the positions reachable in a number of plies. A position
+// reachable in several numbers of plies is counted in each.
+type plyStats struct {
+	positions   int // Number of distinct positions
+	whiteToMove int // Number of positions with white to move, including stalemates
+	blackToMove int // Number of positions with black to move, including stalemates
+	whiteWins   int // Number of positions won by white
+	blackWins   int // Number of positions won by black
+	stalemates  int // Number of positions the side to move has no pawn options at
+}
+
+// spaceNode is what is known of a position during enumeration.
+type spaceNode struct {
+	longest int    // Number of plies in the longest game from the position
+	games   uint64 // Number of distinct games from the position
+}
+
+// spaceEntry is a position at one ply of enumeration.
+type spaceEntry struct {
+	g  grid  // Pawns
+	st state // State
+}
+
+// maxPlies returns an upper bound on the number of events in the history of a
+// game on an m-by-n board. Each move advances a pawn a row, and a pawn can
+// advance m-2 rows before it reaches the last row and wins, so at most 2n(m-2)
+// moves are made before either a final winning move or a stalemate.
+func maxPlies(m, n int) int {
+	return 2*n*(m-2) + 1
+}
+
+// enumerate returns the statistics of the positions reachable from the start of a
+// game on an m-by-n board. Positions are identified by their zobrist hash.
+func enumerate(m, n int) spaceStats {
+	ss := spaceStats{m: m, n: n}
+	nodes := make(map[uint64]*spaceNode)
+	root := newGrid(newBoard(m, n))
+	ss.games = ss.visit(root, whiteTurn, nodes).games
+	ss.longest = nodes[root.zobrist(whiteTurn)].longest
+
+	// Count the positions reachable in each number of plies
+	ply := map[uint64]spaceEntry{root.zobrist(whiteTurn): {g: root, st: whiteTurn}}
+	for 0 < len(ply) {
+		var (
+			ps   plyStats
+			next = make(map[uint64]spaceEntry)
+		)
+
+		for _, e := range ply {
+			ps.positions++
+			switch e.st {
+			case whiteWin:
+				ps.whiteWins++
+				continue
+			case blackWin:
+				ps.blackWins++
+				continue
+			case whiteTurn:
+				ps.whiteToMove++
+			case blackTurn:
+				ps.blackToMove++
+			}
+
+			pos := e.g.pawnOpts(e.st)
+			if len(pos) == 0 {
+				ps.stalemates++
+				continue
+			}
+
+			for _, po := range pos {
+				g, st := e.g.apply(po, e.st)
+				next[g.zobrist(st)] = spaceEntry{g: g, st: st}
+			}
+		}
+
+		ss.plies = append(ss.plies, ps)
+		ply = next
+	}
+
+	return ss
+}
+
+// visit a position, counting it if it has not been visited before, and return
+// what is known of it.
+func (ss *spaceStats) visit(g grid, st state, nodes map[uint64]*spaceNode) *spaceNode {
+	key := g.zobrist(st)
+	if nd, ok := nodes[key]; ok {
+		return nd
+	}
+
+	nd := &spaceNode{games: 1}
+	nodes[key] = nd
+	ss.positions++
+	switch st {
+	case whiteWin:
+		ss.whiteWins++
+		return nd
+	case blackWin:
+		ss.blackWins++
+		return nd
+	case whiteTurn:
+		ss.whiteToMove++
+	case blackTurn:
+		ss.blackToMove++
+	}
+
+	pos := g.pawnOpts(st)
+	if len(pos) == 0 {
+		ss.stalemates++
+		nd.longest = 1
+		return nd
+	}
+
+	ss.pawnOpts += len(pos)
+	if ss.maxBranching < len(pos) {
+		ss.maxBranching = len(pos)
+	}
+
+	nd.games = 0
+	for _, po := range pos {
+		cg, cst := g.apply(po, st)
+		child := ss.visit(cg, cst, nodes)
+		if nd.longest < child.longest+1 {
+			nd.longest = child.longest + 1
+		}
+
+		if nd.games += child.games; nd.games < child.games {
+			nd.games = math.MaxUint64
+		}
+	}
+
+	return nd
+}
+
+// avgBranching returns the average number of pawn options over the positions
+// with pawn options.
+func (ss *spaceStats) avgBranching() float64 {
+	if moving := ss.whiteToMove + ss.blackToMove - ss.stalemates; 0 < moving {
+		return float64(ss.pawnOpts) / float64(moving)
+	}
+
+	return 0
+}
+
+// gamesString returns the number of distinct games, marked with a plus if it
+// saturated.
+func (ss *spaceStats) gamesString() string {
+	if ss.games == math.MaxUint64 {
+		return fmt.Sprintf("%d+", ss.games)
+	}
+
+	return fmt.Sprintf("%d", ss.games)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// termConfig determines how boards are rendered on a terminal. The zero config
+// renders the plain format of board.String.
+type termConfig struct {
+	coords  bool   // Label files and ranks as in the move notation
+	unicode bool   // Draw pawns as chess pawn glyphs
+	color   bool   // Color pawns by side and the squares of the last move with ANSI escape codes, rather than bracketing the squares of the last move
+	flip    bool   // Show the board from black's side
+	compact bool   // Render the board on a single line
+	last    *arrow // Last move, which must change rows; nil if none
+}
+
+// ANSI escape codes
+const (
+	ansiReset     = "\x1b[0m"
+	ansiWhite     = "\x1b[1;97m" // Bold bright white
+	ansiBlack     = "\x1b[1;31m" // Bold red, as black is invisible on dark terminals
+	ansiHighlight = "\x1b[43m"   // Yellow background
+)
+
+// render returns a board rendered on a terminal.
+func (brd board) render(cfg termConfig) string {
+	if !cfg.coords && !cfg.unicode && !cfg.color && !cfg.flip && !cfg.compact && cfg.last == nil {
+		return brd.String()
+	}
+
+	var (
+		m, n  = len(brd), len(brd[0])
+		rows  = make([]int, 0, m) // Rows in the order rendered
+		cols  = make([]int, 0, n) // Columns in the order rendered
+		width = len(strconv.Itoa(m))
+		bldr  = strings.Builder{}
+	)
+
+	for i := 0; i < m; i++ {
+		rows = append(rows, i)
+	}
+
+	for j := 0; j < n; j++ {
+		cols = append(cols, j)
+	}
+
+	if cfg.flip {
+		for a, b := 0, m-1; a < b; a, b = a+1, b-1 {
+			rows[a], rows[b] = rows[b], rows[a]
+		}
+
+		for a, b := 0, n-1; a < b; a, b = a+1, b-1 {
+			cols[a], cols[b] = cols[b], cols[a]
+		}
+	}
+
+	if cfg.compact {
+		for k, i := range rows {
+			if 0 < k {
+				bldr.WriteByte('/')
+			}
+
+			if cfg.coords {
+				bldr.WriteString(strconv.Itoa(m-i) + ":")
+			}
+
+			for _, j := range cols {
+				if sq := cfg.square(brd, i, j, true); !cfg.color && cfg.marked(i, j) {
+					bldr.WriteString("[" + sq + "]")
+				} else {
+					bldr.WriteString(sq)
+				}
+			}
+		}
+
+		return bldr.String()
+	}
+
+	line := strings.Repeat("-+", n) + "\n"
+	margin := ""
+	if cfg.coords {
+		margin = strings.Repeat(" ", width+1)
+	}
+
+	for _, i := range rows {
+		bldr.WriteString(margin + "+" + line)
+		if cfg.coords {
+			bldr.WriteString(padLeft(strconv.Itoa(m-i), width) + " ")
+		}
+
+		// Without color, the square of the last move in this row is bracketed
+		// by its borders, as each move changes rows.
+		border := "|"
+		for _, j := range cols {
+			switch {
+			case !cfg.color && cfg.marked(i, j):
+				border = "["
+			case border == "[":
+				border = "]"
+			default:
+				border = "|"
+			}
+
+			bldr.WriteString(border + cfg.square(brd, i, j, false))
+		}
+
+		if border == "[" {
+			bldr.WriteString("]\n")
+		} else {
+			bldr.WriteString("|\n")
+		}
+	}
+
+	bldr.WriteString(margin + "+" + strings.TrimSuffix(line, "\n"))
+	if cfg.coords {
+		bldr.WriteString("\n" + margin)
+		for _, j := range cols {
+			bldr.WriteString(" " + string(rune('a'+j)))
+		}
+	}
+
+	return bldr.String()
+}
+
+// square returns the rendering of the square at row i and column j of a board.
+// Empty squares are drawn as dots if marked.
+func (cfg termConfig) square(brd board, i, j int, markEmpty bool) string {
+	var s string
+	switch brd[i][j] {
+	case whitePawn:
+		s = "w"
+		if cfg.unicode {
+			s = "♙"
+		}
+
+		if cfg.color {
+			s = ansiWhite + s + ansiReset
+		}
+	case blackPawn:
+		s = "b"
+		if cfg.unicode {
+			s = "♟"
+		}
+
+		if cfg.color {
+			s = ansiBlack + s + ansiReset
+		}
+	default:
+		s = " "
+		if markEmpty {
+			s = "."
+		}
+	}
+
+	if cfg.color && cfg.marked(i, j) {
+		s = ansiHighlight + s + ansiReset
+	}
+
+	return s
+}
+
+// marked returns true if the square at row i and column j is a square of the
+// last move.
+func (cfg termConfig) marked(i, j int) bool {
+	return cfg.last != nil && (i == cfg.last.i0 && j == cfg.last.j0 || i == cfg.last.i1 && j == cfg.last.j1)
+}
+
+// padLeft returns a string padded with spaces on the left to a width.
+func padLeft(s string, width int) string {
+	if len(s) < width {
+		return strings.Repeat(" ", width-len(s)) + s
+	}
+
+	return s
+}
+
+// render returns a game's board and state rendered on a terminal.
+func (gm *game) render(cfg termConfig) string {
+	if cfg.compact {
+		return gm.brd.render(cfg) + " " + stateName(gm.st)
+	}
+
+	return gm.brd.render(cfg) + "\n" + stateName(gm.st)
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Tournament systems
+const (
+	roundRobin = "round-robin" // Every entrant plays every other entrant
+	swiss      = "swiss"       // Entrants with similar scores play each round
+)
+
+// entrant is a player registered in a tournament. Auto players play only one
+// side, so an entrant has a player for each side.
+type entrant struct {
+	name  string // Name the entrant is reported by
+	specs string // Specs of the players, as given
+	white player // Player of the white side
+	black player // Player of the black side
+}
+
+// tournamentConfig determines how a tournament is played.
+type tournamentConfig struct {
+	m        int    // Number of rows
+	n        int    // Number of columns
+	system   string // Either round robin or swiss
+	rounds   int    // Number of rounds of a swiss tournament
+	numGames int    // Number of games per pairing; colors alternate between games
+	seed     int64  // Seed of each game
+}
+
+// pairing is a match of a number of games between two entrants. The first
+// entrant plays white in the first game.
+type pairing struct {
+	Round int  `json:"round"`
+	A     int  `json:"a"`
+	B     int  `json:"b"`
+	Bye   bool `json:"bye,omitempty"`
+}
+
+// standing is an entrant's results over a tournament.
+type standing struct {
+	Entrant    int     `json:"-"`
+	Name       string  `json:"name"`
+	Players    string  `json:"players"`
+	Games      int     `json:"games"`
+	Wins       int     `json:"wins"`
+	Losses     int     `json:"losses"`
+	Stalemates int     `json:"stalemates"`
+	Points     float64 `json:"points"`
+	Byes       int     `json:"byes,omitempty"`
+}
+
+// tournament is the results of a tournament.
+type tournament struct {
+	cfg      tournamentConfig
+	entrants []*entrant
+	pairings []pairing
+	tallies  [][]tally // Outcomes of each entrant against each other entrant, from the first's perspective
+	whites   []int     // Number of games each entrant played white
+	byes     []int     // Number of byes each entrant received
+	records  []*record // Record of each game in the order played
+}
+
+// parseEntrant returns the entrant described by a spec for an m-by-n board. A
+// spec is an optional name followed by an equals sign, then a player spec for
+// both sides or a player spec for white and one for black separated by a comma,
+// such as "champ=white.json,black.json" or "search:4".
+func parseEntrant(spec string, m, n int, seed int64) (*entrant, error) {
+	e := &entrant{name: spec, specs: spec}
+	if i := strings.Index(spec, "="); 0 <= i {
+		e.name, e.specs = spec[:i], spec[i+1:]
+	}
+
+	specs := strings.Split(e.specs, ",")
+	if len(specs) == 1 {
+		specs = append(specs, specs[0])
+	}
+
+	if len(specs) != 2 || e.name == "" {
+		return nil, fmt.Errorf("parseEntrant: invalid entrant %q", spec)
+	}
+
+	var err error
+	if e.white, err = parsePlayer(specs[0], whiteSide, m, n, seed); err != nil {
+		return nil, fmt.Errorf("parseEntrant: %s: %v", e.name, err)
+	}
+
+	if e.black, err = parsePlayer(specs[1], blackSide, m, n, seed); err != nil {
+		return nil, fmt.Errorf("parseEntrant: %s: %v", e.name, err)
+	}
+
+	return e, nil
+}
+
+// playTournament plays a tournament between entrants.
+func playTournament(entrants []*entrant, cfg tournamentConfig) (*tournament, error) {
+	if len(entrants) < 2 {
+		return nil, fmt.Errorf("playTournament: at least two entrants are needed")
+	}
+
+	if cfg.numGames < 1 {
+		return nil, fmt.Errorf("playTournament: invalid number of games %d", cfg.numGames)
+	}
+
+	names := make(map[string]bool)
+	for _, e := range entrants {
+		if names[e.name] {
+			return nil, fmt.Errorf("playTournament: entrants share the name %s", e.name)
+		}
+
+		names[e.name] = true
+	}
+
+	t := &tournament{
+		cfg:      cfg,
+		entrants: entrants,
+		tallies:  make([][]tally, len(entrants)),
+		whites:   make([]int, len(entrants)),
+		byes:     make([]int, len(entrants)),
+	}
+
+	for i := range t.tallies {
+		t.tallies[i] = make([]tally, len(entrants))
+	}
+
+	switch cfg.system {
+	case roundRobin:
+		for r, prs := range roundRobinRounds(len(entrants)) {
+			for _, pr := range prs {
+				pr.Round = r + 1
+				t.play(pr)
+			}
+		}
+	case swiss:
+		if cfg.rounds < 1 {
+			return nil, fmt.Errorf("playTournament: invalid number of rounds %d", cfg.rounds)
+		}
+
+		for r := 1; r <= cfg.rounds; r++ {
+			for _, pr := range t.swissRound(r) {
+				t.play(pr)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("playTournament: unknown system %q", cfg.system)
+	}
+
+	return t, nil
+}
+
+// roundRobinRounds returns the pairings of each round of a round robin between a
+// number of entrants by the circle method. Each entrant plays every other
+// entrant once, and colors alternate from round to round. With an odd number of
+// entrants, one entrant sits out each round.
+func roundRobinRounds(numEntrants int) [][]pairing {
+	k := numEntrants + numEntrants%2 // Entrant k-1 is the bye if the number is odd
+	circle := make([]int, k)
+	for i := range circle {
+		circle[i] = i
+	}
+
+	rounds := make([][]pairing, 0, k-1)
+	for r := 0; r < k-1; r++ {
+		prs := make([]pairing, 0, k/2)
+		for i := 0; i < k/2; i++ {
+			a, b := circle[i], circle[k-1-i]
+			if (i == 0 && r%2 == 1) || (0 < i && i%2 == 1) {
+				a, b = b, a
+			}
+
+			if a < numEntrants && b < numEntrants {
+				prs = append(prs, pairing{A: a, B: b})
+			}
+		}
+
+		rounds = append(rounds, prs)
+
+		// Rotate every entrant but the first
+		last := circle[k-1]
+		copy(circle[2:], circle[1:k-1])
+		circle[1] = last
+	}
+
+	return rounds
+}
+
+// swissRound returns the pairings of a round of a swiss tournament. Entrants are
+// ranked by points and each is paired with the highest ranked entrant it has
+// not yet played, if any. With an odd number of entrants, the lowest ranked
+// entrant with the fewest byes has a bye. The entrant that has played white
+// less often plays white first.
+func (t *tournament) swissRound(r int) []pairing {
+	sts := t.standings()
+	ranked := make([]int, 0, len(sts))
+	for _, st := range sts {
+		ranked = append(ranked, st.Entrant)
+	}
+
+	prs := make([]pairing, 0, len(ranked)/2+1)
+	if len(ranked)%2 == 1 {
+		bye := len(ranked) - 1
+		for i := len(ranked) - 1; 0 <= i; i-- {
+			if t.byes[ranked[i]] < t.byes[ranked[bye]] {
+				bye = i
+			}
+		}
+
+		prs = append(prs, pairing{Round: r, A: ranked[bye], B: ranked[bye], Bye: true})
+		ranked = append(ranked[:bye], ranked[bye+1:]...)
+	}
+
+	for 0 < len(ranked) {
+		a, j := ranked[0], 1
+		for k := 1; k < len(ranked); k++ {
+			if t.tallies[a][ranked[k]].games() == 0 {
+				j = k
+				break
+			}
+		}
+
+		b := ranked[j]
+		if t.whites[b] < t.whites[a] {
+			a, b = b, a
+		}
+
+		prs = append(prs, pairing{Round: r, A: a, B: b})
+		ranked = append(ranked[1:j], ranked[j+1:]...)
+	}
+
+	return prs
+}
+
+// play the games of a pairing. A bye is counted as a win of each game.
+func (t *tournament) play(pr pairing) {
+	t.pairings = append(t.pairings, pr)
+	if pr.Bye {
+		t.byes[pr.A]++
+		return
+	}
+
+	for k := 0; k < t.cfg.numGames; k++ {
+		a, b := pr.A, pr.B
+		if k%2 == 1 {
+			a, b = b, a
+		}
+
+		seed := deriveSeed(t.cfg.seed, int64(len(t.pairings)), int64(k))
+		gm := playSeeded(t.entrants[a].white, t.entrants[b].black, t.cfg.m, t.cfg.n, seed)
+		t.tallies[a][b].add(gm.st, whiteSide)
+		t.tallies[b][a].add(gm.st, blackSide)
+		t.whites[a]++
+		t.records = append(t.records, newRecord(gm, seed, t.entrants[a].name, t.entrants[b].name))
+	}
+}
+
+// standings returns the standings of each entrant, ranked by points, then wins,
+// then the order entrants were registered in.
+func (t *tournament) standings() []standing {
+	sts := make([]standing, 0, len(t.entrants))
+	for i, e := range t.entrants {
+		st := standing{Entrant: i, Name: e.name, Players: e.specs, Byes: t.byes[i]}
+		for _, tly := range t.tallies[i] {
+			st.Games += tly.games()
+			st.Wins += tly.wins
+			st.Losses += tly.losses
+			st.Stalemates += tly.stalemates
+		}
+
+		st.Points = float64(st.Wins) + float64(st.Stalemates)/2 + float64(t.byes[i]*t.cfg.numGames)
+		sts = append(sts, st)
+	}
+
+	sort.SliceStable(sts, func(i, j int) bool {
+		if sts[i].Points != sts[j].Points {
+			return sts[j].Points < sts[i].Points
+		}
+
+		return sts[j].Wins < sts[i].Wins
+	})
+
+	return sts
+}
+
+// points returns the points an entrant earned against another, counting a win as
+// one point and a stalemate as half a point.
+func (t *tournament) points(i, j int) float64 {
+	tly := t.tallies[i][j]
+	return float64(tly.wins) + float64(tly.stalemates)/2
+}
+
+// crosstable returns the cells of a crosstable of the entrants in order of their
+// standings. Each cell holds the points the row's entrant earned against the
+// column's entrant and the number of games they played, or is empty if they
+// did not play.
+func (t *tournament) crosstable(sts []standing) [][]string {
+	cells := make([][]string, 0, len(sts))
+	for _, si := range sts {
+		row := make([]string, 0, len(sts))
+		for _, sj := range sts {
+			if tly := t.tallies[si.Entrant][sj.Entrant]; 0 < tly.games() {
+				row = append(row, fmt.Sprintf("%g/%d", t.points(si.Entrant, sj.Entrant), tly.games()))
+			} else {
+				row = append(row, "")
+			}
+		}
+
+		cells = append(cells, row)
+	}
+
+	return cells
+}
+
+// writeTournamentText writes the standings and crosstable of a tournament as
+// tables.
+func writeTournamentText(w io.Writer, t *tournament) error {
+	sts := t.standings()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "rank\tname\tgames\twins\tlosses\tstalemates\tbyes\tpoints\t")
+	for k, st := range sts {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%d\t%d\t%g\t\n", k+1, st.Name, st.Games, st.Wins, st.Losses, st.Stalemates, st.Byes, st.Points)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprint(tw, "\t")
+	for k := range sts {
+		fmt.Fprintf(tw, "%d\t", k+1)
+	}
+
+	fmt.Fprintln(tw)
+	for k, row := range t.crosstable(sts) {
+		fmt.Fprintf(tw, "%d %s\t", k+1, sts[k].Name)
+		for l, cell := range row {
+			switch {
+			case k == l:
+				cell = "x"
+			case cell == "":
+				cell = "-"
+			}
+
+			fmt.Fprintf(tw, "%s\t", cell)
+		}
+
+		fmt.Fprintln(tw)
+	}
+
+	return tw.Flush()
+}
+
+// writeTournamentCSV writes the standings of a tournament as comma separated
+// values, with a column per entrant holding the crosstable.
+func writeTournamentCSV(w io.Writer, t *tournament) error {
+	sts := t.standings()
+	cw := csv.NewWriter(w)
+	header := []string{"rank", "name", "players", "games", "wins", "losses", "stalemates", "byes", "points"}
+	for _, st := range sts {
+		header = append(header, st.Name)
+	}
+
+	cw.Write(header)
+	for k, row := range t.crosstable(sts) {
+		st := sts[k]
+		cw.Write(append([]string{
+			strconv.Itoa(k + 1),
+			st.Name,
+			st.Players,
+			strconv.Itoa(st.Games),
+			strconv.Itoa(st.Wins),
+			strconv.Itoa(st.Losses),
+			strconv.Itoa(st.Stalemates),
+			strconv.Itoa(st.Byes),
+			strconv.FormatFloat(st.Points, 'g', -1, 64),
+		}, row...))
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// tournamentFile is the JSON form of the results of a tournament.
+type tournamentFile struct {
+	Rows       int        `json:"rows"`
+	Columns    int        `json:"columns"`
+	System     string     `json:"system"`
+	Games      int        `json:"games"`
+	Seed       int64      `json:"seed"`
+	Standings  []standing `json:"standings"`
+	Crosstable [][]string `json:"crosstable"`
+	Pairings   []pairing  `json:"pairings"`
+}
+
+// writeTournamentJSON writes the results of a tournament as JSON. Entrants in
+// pairings are numbered in the order they were registered.
+func writeTournamentJSON(w io.Writer, t *tournament) error {
+	sts := t.standings()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(tournamentFile{
+		Rows:       t.cfg.m,
+		Columns:    t.cfg.n,
+		System:     t.cfg.system,
+		Games:      t.cfg.numGames,
+		Seed:       t.cfg.seed,
+		Standings:  sts,
+		Crosstable: t.crosstable(sts),
+		Pairings:   t.pairings,
+	})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// tournamentCmd plays a tournament between the entrants given as arguments and
+// reports the standings and crosstable.
+func tournamentCmd(args []string) error {
+	var (
+		fs       = flag.NewFlagSet("tournament", flag.ContinueOnError)
+		m        = fs.Int("m", 3, "number of rows")
+		n        = fs.Int("n", 3, "number of columns")
+		system   = fs.String("system", roundRobin, "tournament system (round-robin or swiss)")
+		rounds   = fs.Int("rounds", 3, "number of rounds of a swiss tournament")
+		numGames = fs.Int("games", 2, "number of games per pairing, alternating colors")
+		seed     = fs.Int64("seed", 0, "random seed (0 seeds from the clock)")
+		format   = fs.String("format", "text", "report format (text, csv, or json)")
+		out      = fs.String("out", "", "file the game records are appended to")
+		ratings  = fs.String("ratings", "", "ratings file updated with the games played")
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: hexapawn tournament [flags] entrant entrant...")
+		fmt.Fprintln(fs.Output(), "an entrant is [name=]players, where players is a player for both sides or white,black")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *m < 3 || *n < 3 {
+		return fmt.Errorf("tournament: invalid dimensions %dx%d", *m, *n)
+	}
+
+	var write func(w io.Writer, t *tournament) error
+	switch *format {
+	case "text":
+		write = writeTournamentText
+	case "csv":
+		write = writeTournamentCSV
+	case "json":
+		write = writeTournamentJSON
+	default:
+		return fmt.Errorf("tournament: unknown format %q", *format)
+	}
+
+	if *seed == 0 {
+		*seed = clockSeed()
+	}
+
+	entrants := make([]*entrant, 0, fs.NArg())
+	for _, spec := range fs.Args() {
+		e, err := parseEntrant(spec, *m, *n, *seed)
+		if err != nil {
+			return fmt.Errorf("tournament: %v", err)
+		}
+
+		entrants = append(entrants, e)
+	}
+
+	t, err := playTournament(entrants, tournamentConfig{m: *m, n: *n, system: *system, rounds: *rounds, numGames: *numGames, seed: *seed})
+	if err != nil {
+		return fmt.Errorf("tournament: %v", err)
+	}
+
+	if *out != "" {
+		if err := writeRecords(*out, t.records); err != nil {
+			return fmt.Errorf("tournament: %v", err)
+		}
+	}
+
+	if *ratings != "" {
+		grs, err := recordResults(t.records)
+		if err != nil {
+			return fmt.Errorf("tournament: %v", err)
+		}
+
+		if err := rateGames(*ratings, *m, *n, standardRules, grs); err != nil {
+			return fmt.Errorf("tournament: %v", err)
+		}
+	}
+
+	if err := write(os.Stdout, t); err != nil {
+		return fmt.Errorf("tournament: %v", err)
+	}
+
+	return nil
+}
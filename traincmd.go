@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// resumeFlags are the flags of the train command that may be given when resuming
+// from a checkpoint. The checkpoint determines the others.
+var resumeFlags = map[string]bool{
+	"checkpoint": true,
+	"resume":     true,
+	"out":        true,
+}
+
+// selfOpponent is the training opponent spec that trains an auto player by
+// self-play against a partner for the other side learning from the same games.
+const selfOpponent = "self"
+
+// trainCmd trains an auto player against an opponent in epochs, evaluating it and
+// writing a checkpoint after each epoch. An auto player trained by self-play is
+// trained in a single session and evaluated once it ends.
+func trainCmd(args []string) error {
+	var (
+		fs         = flag.NewFlagSet("train", flag.ContinueOnError)
+		m          = fs.Int("m", 3, "number of rows")
+		n          = fs.Int("n", 3, "number of columns")
+		sd         = fs.String("side", "w", "side to train (w or b)")
+		numGames   = fs.Int("games", 100000, "number of training games")
+		epochGames = fs.Int("epoch", 1000, "number of training games per epoch")
+		lr         = fs.String("lr", "0.1", "learning rate schedule (decay:start[:parameters])")
+		temp       = fs.String("temp", "1", "exploration temperature schedule (decay:start[:parameters])")
+		discount   = fs.Float64("discount", 1, "reward discount per credited move after a move")
+		winR       = fs.Float64("win", 1, "reward for a win")
+		lossR      = fs.Float64("loss", -1, "reward for a loss")
+		staleR     = fs.Float64("stalemate", -1, "reward for a stalemate")
+		allMoves   = fs.Bool("all-moves", false, "credit the opponent's moves as well as the trained side's")
+		opp        = fs.String("opponent", "random", "training opponent (random, solver, search:depth, agent file, or self)")
+		evalOpp    = fs.String("eval", "random", "evaluation opponent (random, solver, search:depth, or agent file)")
+		evalGames  = fs.Int("eval-games", 100, "number of evaluation games per epoch")
+		checkpoint = fs.String("checkpoint", "", "checkpoint file written after each epoch")
+		resume     = fs.Bool("resume", false, "resume training from the checkpoint file")
+		target     = fs.Float64("target", 0, "evaluation score at which to stop early (0 disables)")
+		patience   = fs.Int("patience", 0, "epochs without improvement after which to stop early (0 disables)")
+		minDelta   = fs.Float64("min-delta", 0, "least increase in evaluation score counted as an improvement")
+		out        = fs.String("out", "", "file the trained agent is saved to")
+		workers    = fs.Int("workers", 1, "number of games played concurrently")
+		batch      = fs.Int("batch", 0, "number of games per batch when training in parallel (0 chooses by the number of workers)")
+		seed       = fs.Int64("seed", 0, "random seed (0 seeds from the clock)")
+		pool       = fs.Int("pool", 0, "number of past snapshots of each side kept to play against in self-play (0 disables)")
+		snapshot   = fs.Int("snapshot", 1000, "number of self-play games between snapshots")
+		poolRate   = fs.Float64("pool-rate", 0.5, "probability of playing a past snapshot in self-play")
+		partnerOut = fs.String("partner-out", "", "file the other side's agent trained in self-play is saved to")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var (
+		ap   *autoPlayer
+		ecfg epochConfig
+		prg  progress
+		err  error
+	)
+
+	if *resume {
+		if *checkpoint == "" {
+			return errors.New("train: -resume requires -checkpoint")
+		}
+
+		// Flags the checkpoint determines may not be given again, so resuming
+		// cannot silently change how training continues
+		var set []string
+		fs.Visit(func(f *flag.Flag) {
+			if !resumeFlags[f.Name] {
+				set = append(set, "-"+f.Name)
+			}
+		})
+
+		if 0 < len(set) {
+			return fmt.Errorf("train: the checkpoint determines %s, so they cannot be given with -resume", strings.Join(set, ", "))
+		}
+
+		if ap, ecfg, prg, err = readCheckpoint(*checkpoint); err != nil {
+			return fmt.Errorf("train: %v", err)
+		}
+	} else {
+		if *sd != string(whiteSide) && *sd != string(blackSide) {
+			return fmt.Errorf("train: invalid side %q", *sd)
+		}
+
+		if *m < 3 || *n < 3 {
+			return fmt.Errorf("train: invalid dimensions %dx%d", *m, *n)
+		}
+
+		if *opp == selfOpponent {
+			switch {
+			case *checkpoint != "":
+				return errors.New("train: self-play does not write checkpoints")
+			case 1 < *workers:
+				return errors.New("train: self-play trains serially")
+			case *pool < 0:
+				return fmt.Errorf("train: invalid pool size %d", *pool)
+			case 0 < *pool && *snapshot < 1:
+				return fmt.Errorf("train: invalid number of games between snapshots %d", *snapshot)
+			case *poolRate < 0 || 1 < *poolRate:
+				return fmt.Errorf("train: invalid pool rate %g", *poolRate)
+			}
+		}
+
+		if *seed == 0 {
+			*seed = clockSeed()
+		}
+
+		ecfg = epochConfig{
+			trainConfig: trainConfig{
+				numGames:   *numGames,
+				seed:       *seed,
+				workers:    *workers,
+				batchGames: *batch,
+				credit: creditConfig{
+					discount:        weight(*discount),
+					winReward:       weight(*winR),
+					lossReward:      weight(*lossR),
+					stalemateReward: weight(*staleR),
+					allMoves:        *allMoves,
+				},
+			},
+			opp:        *opp,
+			evalOpp:    *evalOpp,
+			epochGames: *epochGames,
+			evalGames:  *evalGames,
+			target:     *target,
+			patience:   *patience,
+			minDelta:   *minDelta,
+		}
+
+		if ecfg.learningRate, err = parseSchedule(*lr); err != nil {
+			return fmt.Errorf("train: learning rate: %v", err)
+		}
+
+		if ecfg.temperature, err = parseSchedule(*temp); err != nil {
+			return fmt.Errorf("train: temperature: %v", err)
+		}
+
+		ap = newAutoPlayer(side((*sd)[0]), *m, *n, ecfg.seed)
+	}
+
+	ecfg.checkpoint = *checkpoint
+	ecfg.logger = log.New(os.Stderr, "", log.LstdFlags)
+
+	oppSd := whiteSide
+	if ap.sd == whiteSide {
+		oppSd = blackSide
+	}
+
+	evalPlayer, err := parsePlayer(ecfg.evalOpp, oppSd, ap.m, ap.n, deriveSeed(ecfg.seed, 2))
+	if err != nil {
+		return fmt.Errorf("train: evaluation opponent: %v", err)
+	}
+
+	if ecfg.opp == selfOpponent {
+		partner := newAutoPlayer(oppSd, ap.m, ap.n, deriveSeed(ecfg.seed, 3))
+		white, black := ap, partner
+		if ap.sd == blackSide {
+			white, black = partner, ap
+		}
+
+		selfPlay(white, black, selfPlayConfig{trainConfig: ecfg.trainConfig, poolSize: *pool, snapshotEvery: *snapshot, poolRate: *poolRate})
+		tly := ap.evalMatch(evalPlayer, ecfg.evalGames, deriveSeed(ecfg.seed, 1, 2))
+		if *out != "" {
+			if err := saveAutoPlayer(ap, *out); err != nil {
+				return fmt.Errorf("train: %v", err)
+			}
+		}
+
+		if *partnerOut != "" {
+			if err := saveAutoPlayer(partner, *partnerOut); err != nil {
+				return fmt.Errorf("train: %v", err)
+			}
+		}
+
+		fmt.Printf("trained %d games of self-play with seed %d, %s\n", ecfg.numGames, ecfg.seed, tly)
+		return nil
+	}
+
+	trainOpp, err := parsePlayer(ecfg.opp, oppSd, ap.m, ap.n, deriveSeed(ecfg.seed, 1))
+	if err != nil {
+		return fmt.Errorf("train: opponent: %v", err)
+	}
+
+	if prg, err = ap.trainEpochs(trainOpp, evalPlayer, ecfg, prg); err != nil {
+		return fmt.Errorf("train: %v", err)
+	}
+
+	if *out != "" {
+		if err := saveAutoPlayer(ap, *out); err != nil {
+			return fmt.Errorf("train: %v", err)
+		}
+	}
+
+	fmt.Printf("trained %d games in %d epochs with seed %d, best score %.3f\n", prg.gamesPlayed, prg.epoch, ecfg.seed, prg.bestScore)
+	return nil
+}
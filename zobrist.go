@@ -0,0 +1,82 @@
+package main
+
+import "math/bits"
+
+// A zobrist hash identifies a board and state by the exclusive or of a key for
+// each pawn on its square and a key for the state. Moving a pawn alters only the
+// keys of the squares it moves from and to and of the state, so a game's hash is
+// updated as each move is made rather than recomputed. Keys are derived from the
+// square, pawn, state, and board size alone, so hashes are stable across runs,
+// equal for boards and bitboards representing the same position, and distinct
+// for boards of different sizes with the same pawns on the same squares.
+//
+// Positions are identified by their hash alone wherever they are stored or
+// looked up. A collision among the positions of any board small enough to
+// search or train on is vanishingly unlikely with 64-bit hashes, so a matching
+// hash is not checked against the board.
+
+// zobristPawn returns the key of a pawn on the square at row i and column j.
+func zobristPawn(i, j int, p pawn) uint64 {
+	return splitmix64(uint64(i)<<32 | uint64(j)<<8 | uint64(p))
+}
+
+// zobristDims returns the key of an m-by-n board.
+func zobristDims(m, n int) uint64 {
+	return splitmix64(1<<62 | uint64(m)<<32 | uint64(n))
+}
+
+// zobristState returns the key of a state. White to move has no key, so a board
+// with white to move hashes to the keys of its pawns alone.
+func zobristState(st state) uint64 {
+	if st == whiteTurn {
+		return 0
+	}
+
+	return splitmix64(1<<63 | uint64(st))
+}
+
+// zobrist returns the zobrist hash of a board and state.
+func zobrist(brd board, st state) uint64 {
+	h := zobristDims(len(brd), len(brd[0])) ^ zobristState(st)
+	for i := range brd {
+		for j, p := range brd[i] {
+			if p != space {
+				h ^= zobristPawn(i, j, p)
+			}
+		}
+	}
+
+	return h
+}
+
+// zobrist returns the zobrist hash of a bitboard and state. It is equal to the
+// zobrist hash of the board the bitboard represents.
+func (bb bitboard) zobrist(st state) uint64 {
+	h := zobristDims(bb.m, bb.n) ^ zobristState(st)
+	for mask := bb.white; mask != 0; mask &= mask - 1 {
+		sq := bits.TrailingZeros64(mask)
+		h ^= zobristPawn(sq/bb.n, sq%bb.n, whitePawn)
+	}
+
+	for mask := bb.black; mask != 0; mask &= mask - 1 {
+		sq := bits.TrailingZeros64(mask)
+		h ^= zobristPawn(sq/bb.n, sq%bb.n, blackPawn)
+	}
+
+	return h
+}
+
+// zobrist returns the zobrist hash of a board and state.
+func (brd board) zobrist(st state) uint64 {
+	return zobrist(brd, st)
+}
+
+// key returns the zobrist hash of a position. Positions of a game carry the
+// game's hash, so their boards are not walked.
+func (psn *position) key() uint64 {
+	if psn.hsh != 0 {
+		return psn.hsh
+	}
+
+	return zobrist(psn.brd, psn.st)
+}
@@ -0,0 +1,110 @@
+package main
+
+import "testing"
+
+// TestZobristGrids checks that boards and bitboards representing the same
+// position hash equally throughout the game trees of each grid position.
+func TestZobristGrids(t *testing.T) {
+	for _, gp := range gridPositions {
+		brd, st, err := parsePosition(gp.psn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		compareZobrist(t, copyBoard(brd), toBitboard(brd), st, gp.depth)
+	}
+}
+
+// compareZobrist walks the game trees of a board and a bitboard representing the
+// same position in lockstep, failing the test where their hashes differ.
+func compareZobrist(t *testing.T, brd, bb grid, st state, depth int) {
+	t.Helper()
+	if brd.zobrist(st) != bb.zobrist(st) {
+		t.Fatalf("%v\n%s: board hashes to %x, bitboard to %x", brd.toBoard(), stateName(st), brd.zobrist(st), bb.zobrist(st))
+	}
+
+	if depth == 0 || st != whiteTurn && st != blackTurn {
+		return
+	}
+
+	for _, po := range brd.pawnOpts(st) {
+		brdChild, brdSt := brd.apply(po, st)
+		bbChild, _ := bb.apply(po, st)
+		compareZobrist(t, brdChild, bbChild, brdSt, depth-1)
+	}
+}
+
+// TestZobristGame checks that a game's incremental hash equals the hash of its
+// board and state after each move and is restored by each undo.
+func TestZobristGame(t *testing.T) {
+	for _, gp := range gridPositions {
+		brd, st, err := parsePosition(gp.psn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		walkZobrist(t, newGameAt(brd, st, cvc), gp.depth)
+	}
+}
+
+// walkZobrist walks the game tree of a game by moving and undoing, failing the
+// test where the game's hash differs from the hash of its board and state.
+func walkZobrist(t *testing.T, gm *game, depth int) {
+	t.Helper()
+	if gm.key() != zobrist(gm.brd, gm.st) {
+		t.Fatalf("%v\n%s: game hash %x, expected %x", gm.brd, stateName(gm.st), gm.key(), zobrist(gm.brd, gm.st))
+	}
+
+	if depth == 0 || gm.st != whiteTurn && gm.st != blackTurn {
+		return
+	}
+
+	pos := gm.pawnOpts()
+	if len(pos) == 0 {
+		gm.move(&event{}) // Stalemate
+		walkZobrist(t, gm, 0)
+		gm.undo()
+	}
+
+	for _, po := range pos {
+		key := gm.key()
+		gm.move(&event{poSlc: po})
+		walkZobrist(t, gm, depth-1)
+		gm.undo()
+		if gm.key() != key {
+			t.Fatalf("%v\n%s: undo restored hash %x, expected %x", gm.brd, stateName(gm.st), gm.key(), key)
+		}
+	}
+}
+
+// TestZobristDims checks that boards of different sizes with the same pawns on
+// the same squares hash differently.
+func TestZobristDims(t *testing.T) {
+	tests := []struct {
+		a, b string // Positions in notation
+	}{
+		{a: "b2/3/w2 w", b: "b2/3/w2/3 w"},
+		{a: "b2/3/w2 w", b: "b3/4/w3 w"},
+		{a: "bbb/3/www w", b: "bbb1/4/www1 w"},
+	}
+
+	for _, test := range tests {
+		aBrd, aSt, err := parsePosition(test.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bBrd, bSt, err := parsePosition(test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if zobrist(aBrd, aSt) == zobrist(bBrd, bSt) {
+			t.Errorf("%q and %q hash equally", test.a, test.b)
+		}
+
+		if toBitboard(aBrd).zobrist(aSt) == toBitboard(bBrd).zobrist(bSt) {
+			t.Errorf("%q and %q hash equally as bitboards", test.a, test.b)
+		}
+	}
+}